@@ -2,17 +2,14 @@ package adventure
 
 import (
 	"encoding/json"
+	"fmt"
 	"io/ioutil"
-	"log"
-	"os"
-	"path"
+	"sort"
 	"strings"
 )
 
-var (
-	// adventures Stores all loadable adventures.
-	adventures []*Adventure
-)
+// IntroArc is the ID of the arc every adventure starts with.
+const IntroArc = "intro"
 
 // Adventure structure represents a whole adventure which is intended to be
 // stored in a single JSON file. An adventure is made up of one or more arcs.
@@ -22,163 +19,190 @@ type Adventure struct {
 	Arcs     []Arc
 }
 
-// New returns the adventure indicated by its name.
-func New(name, path string) *Adventure {
+// New returns the adventure indicated by its name, read from the JSON file
+// at path. The adventure is validated, see Validate.
+func New(name, path string) (*Adventure, error) {
+	a, err := Read(name, path)
+	if err != nil {
+		return nil, err
+	}
+	if err := a.Validate(); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// Read is like New but doesn't validate the adventure, e.g. to examine
+// what is wrong with it.
+func Read(name, path string) (*Adventure, error) {
 	arcs, err := parseArcs(path)
 	if err != nil {
-		log.Fatalf("error parsing arcs: %s", err)
+		return nil, fmt.Errorf("error parsing arcs of %s: %w", path, err)
 	}
-	a := &Adventure{Name: name, FilePath: path, Arcs: arcs}
-	return a
+	return &Adventure{Name: name, FilePath: path, Arcs: arcs}, nil
 }
 
-func RTFV(name string) *Adventure {
-	var result *Adventure
-	for _, a := range adventures {
-		if a.Name == name {
-			result = a
+// Arc returns the arc with the given ID, ok is false if there is none.
+func (a *Adventure) Arc(id string) (arc Arc, ok bool) {
+	for _, arc := range a.Arcs {
+		if arc.ID == id {
+			return arc, true
 		}
 	}
-	return result
+	return Arc{}, false
 }
 
-// The Loader interface.
-type Loader interface {
-	Load(paths ...string) error
+// reachable returns the IDs of all arcs which can be reached from the
+// intro by following the options, regardless of their conditions.
+func (a *Adventure) reachable() map[string]bool {
+	reached := make(map[string]bool, len(a.Arcs))
+	queue := []string{IntroArc}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		arc, ok := a.Arc(id)
+		if !ok || reached[id] {
+			continue
+		}
+		reached[id] = true
+		for _, o := range arc.Options {
+			queue = append(queue, o.NextArcName)
+		}
+	}
+	return reached
 }
 
-// Service serves adventures.
-type Service interface {
-	Load(paths ...string) error
-	ListAdventureNames() []string
+// ValidationError lists everything wrong with an adventure.
+type ValidationError struct {
+	Adventure string
+	Problems  []string
 }
 
-// Load loads the directory intended to hold the adventures in JSON format and fill
-// adventures list with their names and adventureToFilePath with corresponding data.
-// If paths is specified it will read from those instead.
-func Load(paths ...string) error {
-	var cwd string
-	if paths == nil {
-		// get directory where adventure files are located
-		// normally <programs root dir>/adventuers, maybe later changeable via flag
-
-		// obtain file path to current working directory of the program (root dir)
-		var err error
-		if cwd, err = os.Getwd(); err != nil {
-			return err
-		}
-	}
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("invalid adventure %q: %s", e.Adventure, strings.Join(e.Problems, "; "))
+}
 
-	// make directory out of file path with actual adventures directory appended
-	adventuresDir := path.Join(cwd, "adventures")
-	dir, err := os.Open(adventuresDir)
-	if err != nil {
-		return err
-	}
+// Validate checks that the adventure has an intro arc, that every option
+// leads to an existing arc with valid conditions and effects and that
+// every arc can be reached from the intro, regardless of conditions. All
+// problems found are reported by a *ValidationError.
+func (a *Adventure) Validate() error {
+	var problems []string
 
-	// get content of directory
-	dirContent, err := dir.Readdirnames(0)
-	if err != nil {
-		return err
+	arcs := make(map[string]Arc, len(a.Arcs))
+	for _, arc := range a.Arcs {
+		arcs[arc.ID] = arc
+	}
+	if _, ok := arcs[IntroArc]; !ok {
+		problems = append(problems, fmt.Sprintf("missing %q arc", IntroArc))
 	}
 
-	// read file names, only include json files
-	for _, f := range dirContent {
-		// treat each file name as the adventures name,
-		// create new Adventure object and write in adventures list
-		if path.Ext(f) == ".json" {
-			// format name a bit
-			n, p := f[:len(f)-len(".json")], path.Join(adventuresDir, f)
-			n = strings.ReplaceAll(n, "-", " ")
-			// fill data variables
-			a := New(n, p)
-			adventures = append(adventures, a)
+	reached := a.reachable()
+	for _, arc := range a.Arcs {
+		for i, o := range arc.Options {
+			if _, ok := arcs[o.NextArcName]; !ok {
+				problems = append(problems, fmt.Sprintf("option %d of arc %q leads to unknown arc %q", i+1, arc.ID, o.NextArcName))
+			}
+			for _, c := range o.Requires {
+				if _, err := condition(c, nil); err != nil {
+					problems = append(problems, fmt.Sprintf("option %d of arc %q: %s", i+1, arc.ID, err))
+				}
+			}
+			for _, e := range o.Effects {
+				if err := effect(e, Vars{}); err != nil {
+					problems = append(problems, fmt.Sprintf("option %d of arc %q: %s", i+1, arc.ID, err))
+				}
+			}
+		}
+		// without intro, nothing is reachable and saying so adds nothing
+		if !reached[arc.ID] && len(reached) > 0 {
+			problems = append(problems, fmt.Sprintf("arc %q is unreachable", arc.ID))
 		}
 	}
 
+	if len(problems) > 0 {
+		return &ValidationError{Adventure: a.Name, Problems: problems}
+	}
 	return nil
 }
 
-// ListAdventureNames returns a list of the names of all possible adventures.
+// RTFV returns the adventure with the given name from the DefaultStore,
+// nil if there is none.
+func RTFV(name string) *Adventure {
+	return DefaultStore.RTFV(name)
+}
+
+// The Loader interface.
+type Loader interface {
+	Load(paths ...string) error
+}
+
+// Service serves adventures.
+type Service interface {
+	Load(paths ...string) error
+	ListAdventureNames() []string
+	RTFV(name string) *Adventure
+}
+
+// Load loads the adventures into the DefaultStore, see Store.Load.
+func Load(paths ...string) error {
+	return DefaultStore.Load(paths...)
+}
+
+// ListAdventureNames returns a list of the names of all adventures in the
+// DefaultStore.
 func ListAdventureNames() []string {
-	var names []string
-	for _, a := range adventures {
-		names = append(names, a.Name)
-	}
-	return names
+	return DefaultStore.ListAdventureNames()
 }
 
 // Arc structure represents a single arc of an adventure.
 type Arc struct {
-	ID      string
+	ID      string   `json:"-"`
 	Title   string   `json:"title"`
 	Story   []string `json:"story"`
 	Options []Option `json:"options"`
 }
 
+// Option leads from one arc to the next. An option may require story
+// variables to be set to be available and set variables when chosen, see
+// Vars.
 type Option struct {
-	NextArcText string `json:"text"`
-	NextArcName string `json:"arc"`
+	NextArcText string   `json:"text"`
+	NextArcName string   `json:"arc"`
+	Requires    []string `json:"requires,omitempty"`
+	Effects     []string `json:"effects,omitempty"`
 }
 
 // ------------- Unexported Stuff -------------
 
 // parseArcs parses the JSON data for an adventure and returns
-// a list of arcs.
+// a list of arcs, starting with the intro and sorted by ID otherwise.
 func parseArcs(adventurePath string) ([]Arc, error) {
 	// preparing JSON file storing adventure
 	content, err := ioutil.ReadFile(adventurePath)
 	if err != nil {
-		log.Fatalf("error reading file: %s", err)
+		return nil, fmt.Errorf("error reading file: %w", err)
 	}
 
 	// As the JSON is in an unconventional format
 	// where arc IDs itself represent the key to the arc object,
-	// the parsing process is a bit more involving and uses an empty
-	// interface as a temporary main container for the arcs.
-	var data interface{}
+	// the arcs are decoded into a map keyed by their ID first.
+	var data map[string]Arc
 	if err := json.Unmarshal(content, &data); err != nil {
-		log.Fatalf("error unmarshalling json: %s", err)
+		return nil, fmt.Errorf("error unmarshalling json: %w", err)
 	}
 
-	// temporary container for data castet to a map
-	container := data.(map[string]interface{})
-
-	// manually parse the data by casting
-	var arcs []Arc = make([]Arc, 0)
-	for k, v := range container {
-		values := v.(map[string]interface{})
-
-		// story case
-		sTmp := values["story"].([]interface{})
-		story := make([]string, 0)
-		for _, s := range sTmp {
-			story = append(story, s.(string))
-		}
-
-		// options case
-		oTmp := values["options"].([]interface{})
-		options := make([]Option, 0)
-		for _, tmp := range oTmp {
-			o := tmp.(map[string]interface{})
-
-			option := Option{
-				NextArcText: o["text"].(string),
-				NextArcName: o["arc"].(string),
-			}
-
-			options = append(options, option)
-		}
-
-		a := &Arc{
-			ID:      k,
-			Title:   values["title"].(string),
-			Story:   story,
-			Options: options,
-		}
-
-		arcs = append(arcs, *a)
+	arcs := make([]Arc, 0, len(data))
+	for id, arc := range data {
+		arc.ID = id
+		arcs = append(arcs, arc)
 	}
+	sort.Slice(arcs, func(i, j int) bool {
+		if (arcs[i].ID == IntroArc) != (arcs[j].ID == IntroArc) {
+			return arcs[i].ID == IntroArc
+		}
+		return arcs[i].ID < arcs[j].ID
+	})
 
 	return arcs, nil
 }
@@ -0,0 +1,271 @@
+package adventure
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// Analysis describes the shape of an adventure's graph of arcs, which are
+// connected by their options.
+type Analysis struct {
+	// DeadEnds are the arcs without options, where the story ends.
+	DeadEnds []string
+
+	// Unreachable are the arcs which can't be reached from the intro.
+	Unreachable []string
+
+	// Cycles are the groups of arcs which can be reached from each other,
+	// so the player can go around in circles.
+	Cycles [][]string
+}
+
+// Analyze analyzes the graph of the adventure, ignoring the conditions of
+// options.
+func (a *Adventure) Analyze() Analysis {
+	var an Analysis
+	reached := a.reachable()
+	for _, arc := range a.Arcs {
+		if len(arc.Options) == 0 {
+			an.DeadEnds = append(an.DeadEnds, arc.ID)
+		}
+		if !reached[arc.ID] {
+			an.Unreachable = append(an.Unreachable, arc.ID)
+		}
+	}
+	an.Cycles = a.cycles()
+	return an
+}
+
+// cycles returns the strongly connected components of the graph which
+// contain a cycle, using Tarjan's algorithm.
+func (a *Adventure) cycles() [][]string {
+	var (
+		index   = make(map[string]int)
+		low     = make(map[string]int)
+		onStack = make(map[string]bool)
+		stack   []string
+		cycles  [][]string
+	)
+
+	var connect func(id string)
+	connect = func(id string) {
+		index[id], low[id] = len(index), len(index)
+		stack = append(stack, id)
+		onStack[id] = true
+
+		arc, _ := a.Arc(id)
+		self := false
+		for _, o := range arc.Options {
+			next := o.NextArcName
+			if _, ok := a.Arc(next); !ok {
+				continue
+			}
+			self = self || next == id
+			if _, ok := index[next]; !ok {
+				connect(next)
+				if low[next] < low[id] {
+					low[id] = low[next]
+				}
+			} else if onStack[next] && index[next] < low[id] {
+				low[id] = index[next]
+			}
+		}
+
+		if low[id] != index[id] {
+			return
+		}
+		var comp []string
+		for {
+			top := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[top] = false
+			comp = append(comp, top)
+			if top == id {
+				break
+			}
+		}
+		if len(comp) > 1 || self {
+			sort.Strings(comp)
+			cycles = append(cycles, comp)
+		}
+	}
+
+	for _, arc := range a.Arcs {
+		if _, ok := index[arc.ID]; !ok {
+			connect(arc.ID)
+		}
+	}
+	return cycles
+}
+
+// edge is an option as shown in a graph.
+type edge struct {
+	from, to string
+	label    string
+	cycle    bool
+	dangling bool
+}
+
+// edges returns all options of the adventure as edges, labeled with
+// their conditions and effects.
+func (a *Adventure) edges(an Analysis) []edge {
+	component := make(map[string]int)
+	for i, c := range an.Cycles {
+		for _, id := range c {
+			component[id] = i + 1
+		}
+	}
+
+	var edges []edge
+	for _, arc := range a.Arcs {
+		for _, o := range arc.Options {
+			var label []string
+			if len(o.Requires) > 0 {
+				label = append(label, "if "+strings.Join(o.Requires, ", "))
+			}
+			if len(o.Effects) > 0 {
+				label = append(label, "do "+strings.Join(o.Effects, ", "))
+			}
+			_, known := a.Arc(o.NextArcName)
+			c := component[arc.ID]
+			edges = append(edges, edge{
+				from:     arc.ID,
+				to:       o.NextArcName,
+				label:    strings.Join(label, "; "),
+				cycle:    c != 0 && c == component[o.NextArcName],
+				dangling: !known,
+			})
+		}
+	}
+	return edges
+}
+
+// WriteDOT writes the graph of the adventure to w in the Graphviz DOT
+// language. The intro is drawn bold, dead ends are filled red, unreachable
+// arcs, even if dead ends, are dashed and gray and options going around in
+// a cycle are orange. Options leading to unknown arcs point to a red
+// dashed node.
+func (a *Adventure) WriteDOT(w io.Writer) error {
+	an := a.Analyze()
+	b := bufio.NewWriter(w)
+
+	fmt.Fprintf(b, "digraph %s {\n", dotQuote(a.Name))
+	fmt.Fprintf(b, "\tnode [shape=box];\n")
+	for _, arc := range a.Arcs {
+		var attrs []string
+		attrs = append(attrs, "label="+dotQuote(arc.Title+"\n("+arc.ID+")"))
+		switch {
+		case contains(an.Unreachable, arc.ID):
+			attrs = append(attrs, `style="dashed,filled"`, "fillcolor=gray90", "color=gray")
+		case contains(an.DeadEnds, arc.ID):
+			attrs = append(attrs, "style=filled", "fillcolor=lightcoral")
+		case arc.ID == IntroArc:
+			attrs = append(attrs, "style=bold")
+		}
+		fmt.Fprintf(b, "\t%s [%s];\n", dotQuote(arc.ID), strings.Join(attrs, ", "))
+	}
+
+	for _, e := range a.edges(an) {
+		var attrs []string
+		if e.label != "" {
+			attrs = append(attrs, "label="+dotQuote(e.label))
+		}
+		if e.cycle {
+			attrs = append(attrs, "color=orange")
+		}
+		if e.dangling {
+			attrs = append(attrs, "color=red", "style=dashed")
+			fmt.Fprintf(b, "\t%s [color=red, style=dashed];\n", dotQuote(e.to))
+		}
+		fmt.Fprintf(b, "\t%s -> %s", dotQuote(e.from), dotQuote(e.to))
+		if len(attrs) > 0 {
+			fmt.Fprintf(b, " [%s]", strings.Join(attrs, ", "))
+		}
+		fmt.Fprintf(b, ";\n")
+	}
+	fmt.Fprintf(b, "}\n")
+	return b.Flush()
+}
+
+// WriteMermaid writes the graph of the adventure to w as a Mermaid
+// flowchart, highlighted like by WriteDOT.
+func (a *Adventure) WriteMermaid(w io.Writer) error {
+	an := a.Analyze()
+	b := bufio.NewWriter(w)
+
+	// Mermaid is picky about node IDs, so arcs are numbered instead
+	ids := make(map[string]string)
+	node := func(id string) string {
+		if n, ok := ids[id]; ok {
+			return n
+		}
+		ids[id] = fmt.Sprintf("arc%d", len(ids))
+		return ids[id]
+	}
+
+	fmt.Fprintf(b, "---\ntitle: %s\n---\n", a.Name)
+	fmt.Fprintf(b, "flowchart TD\n")
+	for _, arc := range a.Arcs {
+		fmt.Fprintf(b, "\t%s[%s]\n", node(arc.ID), mermaidQuote(arc.Title+"<br>("+arc.ID+")"))
+	}
+
+	var cycle []string
+	for i, e := range a.edges(an) {
+		to := node(e.to)
+		if e.dangling {
+			fmt.Fprintf(b, "\t%s[%s]:::dangling\n", to, mermaidQuote(e.to))
+		}
+		if e.label != "" {
+			fmt.Fprintf(b, "\t%s -->|%s| %s\n", node(e.from), mermaidQuote(e.label), to)
+		} else {
+			fmt.Fprintf(b, "\t%s --> %s\n", node(e.from), to)
+		}
+		if e.cycle {
+			cycle = append(cycle, fmt.Sprint(i))
+		}
+	}
+
+	fmt.Fprintf(b, "\tclassDef intro stroke-width:3px\n")
+	fmt.Fprintf(b, "\tclassDef deadEnd fill:lightcoral\n")
+	fmt.Fprintf(b, "\tclassDef unreachable fill:#eee,stroke:gray,stroke-dasharray:5 5\n")
+	fmt.Fprintf(b, "\tclassDef dangling stroke:red,stroke-dasharray:5 5\n")
+	if _, ok := a.Arc(IntroArc); ok {
+		fmt.Fprintf(b, "\tclass %s intro\n", node(IntroArc))
+	}
+	for _, id := range an.DeadEnds {
+		if !contains(an.Unreachable, id) {
+			fmt.Fprintf(b, "\tclass %s deadEnd\n", node(id))
+		}
+	}
+	for _, id := range an.Unreachable {
+		fmt.Fprintf(b, "\tclass %s unreachable\n", node(id))
+	}
+	if len(cycle) > 0 {
+		fmt.Fprintf(b, "\tlinkStyle %s stroke:orange\n", strings.Join(cycle, ","))
+	}
+	return b.Flush()
+}
+
+// dotQuote quotes s as a DOT string.
+func dotQuote(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	return `"` + r.Replace(s) + `"`
+}
+
+// mermaidQuote quotes s as a Mermaid label.
+func mermaidQuote(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, "#quot;") + `"`
+}
+
+// contains reports whether ids contains id.
+func contains(ids []string, id string) bool {
+	for _, i := range ids {
+		if i == id {
+			return true
+		}
+	}
+	return false
+}
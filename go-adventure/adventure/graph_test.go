@@ -0,0 +1,94 @@
+package adventure
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// maze is an adventure with a self-loop, a cycle of two arcs, a dangling
+// option and an unreachable arc.
+var maze = &Adventure{
+	Name: "maze",
+	Arcs: []Arc{
+		{ID: IntroArc, Title: "Entrance", Options: []Option{
+			{NextArcText: "Enter", NextArcName: "loop"},
+			{NextArcText: "Climb", NextArcName: "nowhere"},
+		}},
+		{ID: "loop", Title: "Loop", Options: []Option{
+			{NextArcText: "Again", NextArcName: "loop"},
+			{NextArcText: "On", NextArcName: "a"},
+		}},
+		{ID: "a", Title: "A", Options: []Option{
+			{NextArcText: "Unlock", NextArcName: "b", Requires: []string{"key"}, Effects: []string{"-key"}},
+		}},
+		{ID: "b", Title: "B", Options: []Option{
+			{NextArcText: "Back", NextArcName: "a"},
+			{NextArcText: "Out", NextArcName: "end"},
+		}},
+		{ID: "end", Title: "Exit"},
+		{ID: "lost", Title: "Lost"},
+	},
+}
+
+func TestAnalyze(t *testing.T) {
+	got := maze.Analyze()
+	want := Analysis{
+		DeadEnds:    []string{"end", "lost"},
+		Unreachable: []string{"lost"},
+		Cycles:      [][]string{{"a", "b"}, {"loop"}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestWriteDOT(t *testing.T) {
+	var b bytes.Buffer
+	if err := maze.WriteDOT(&b); err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range []string{
+		`"intro" [label="Entrance\n(intro)", style=bold];`,
+		`"end" [label="Exit\n(end)", style=filled, fillcolor=lightcoral];`,
+		`"lost" [label="Lost\n(lost)", style="dashed,filled", fillcolor=gray90, color=gray];`,
+		`"nowhere" [color=red, style=dashed];`,
+		`"intro" -> "nowhere" [color=red, style=dashed];`,
+		`"intro" -> "loop";`,
+		`"loop" -> "loop" [color=orange];`,
+		`"loop" -> "a";`,
+		`"a" -> "b" [label="if key; do -key", color=orange];`,
+		`"b" -> "a" [color=orange];`,
+		`"b" -> "end";`,
+	} {
+		if !strings.Contains(b.String(), "\t"+line+"\n") {
+			t.Errorf("missing %s in\n%s", line, b.String())
+		}
+	}
+}
+
+func TestWriteMermaid(t *testing.T) {
+	var b bytes.Buffer
+	if err := maze.WriteMermaid(&b); err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range []string{
+		`arc0["Entrance<br>(intro)"]`,
+		`arc6["nowhere"]:::dangling`,
+		`arc0 --> arc6`,
+		`arc1 --> arc1`,
+		`arc2 -->|"if key; do -key"| arc3`,
+		`class arc0 intro`,
+		`class arc4 deadEnd`,
+		`class arc5 unreachable`,
+		`linkStyle 2,4,5 stroke:orange`,
+	} {
+		if !strings.Contains(b.String(), "\t"+line+"\n") {
+			t.Errorf("missing %s in\n%s", line, b.String())
+		}
+	}
+	if strings.Contains(b.String(), "class arc5 deadEnd") {
+		t.Errorf("unreachable dead end is highlighted as dead end:\n%s", b.String())
+	}
+}
@@ -0,0 +1,58 @@
+package adventure
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Play plays the adventure in a terminal, starting at the intro arc with
+// no story variables set. Each arc is written to out with its title, story
+// and the numbered options available, then the number of the chosen option
+// is read from in, one per line. Entering q quits. Play returns once an arc
+// without options is reached or in is exhausted.
+func (a *Adventure) Play(in io.Reader, out io.Writer) error {
+	s := bufio.NewScanner(in)
+	id, vars := IntroArc, Vars{}
+	for {
+		arc, ok := a.Arc(id)
+		if !ok {
+			return fmt.Errorf("adventure %q has no arc %q", a.Name, id)
+		}
+
+		fmt.Fprintf(out, "\n%s\n%s\n\n", arc.Title, strings.Repeat("=", len(arc.Title)))
+		for _, p := range arc.Story {
+			fmt.Fprintf(out, "%s\n\n", p)
+		}
+		options := arc.Available(vars)
+		if len(options) == 0 {
+			fmt.Fprintln(out, "The End.")
+			return nil
+		}
+		for i, o := range options {
+			fmt.Fprintf(out, "%d) %s\n", i+1, o.NextArcText)
+		}
+
+		// ask until the choice is one of the options
+		for {
+			fmt.Fprint(out, "> ")
+			if !s.Scan() {
+				fmt.Fprintln(out)
+				return s.Err()
+			}
+			input := strings.TrimSpace(s.Text())
+			if input == "q" {
+				return nil
+			}
+			n, err := strconv.Atoi(input)
+			if err == nil && n >= 1 && n <= len(options) {
+				options[n-1].Apply(vars)
+				id = options[n-1].NextArcName
+				break
+			}
+			fmt.Fprintf(out, "choose an option from 1 to %d or q to quit\n", len(options))
+		}
+	}
+}
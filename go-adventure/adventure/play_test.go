@@ -0,0 +1,98 @@
+package adventure
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// cave is a small adventure with a choice leading to one of two endings.
+var cave = &Adventure{
+	Name: "cave",
+	Arcs: []Arc{
+		{ID: IntroArc, Title: "The Cave", Story: []string{"A dark cave."}, Options: []Option{
+			{NextArcText: "Go in", NextArcName: "inside"},
+			{NextArcText: "Go home", NextArcName: "home"},
+		}},
+		{ID: "inside", Title: "Inside", Story: []string{"It is dark."}, Options: []Option{
+			{NextArcText: "Go home", NextArcName: "home"},
+		}},
+		{ID: "home", Title: "Home", Story: []string{"Safe and sound."}},
+	},
+}
+
+func TestPlay(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []string
+		not   []string
+	}{
+		{
+			name:  "reach an ending",
+			input: "1\n1\n",
+			want:  []string{"The Cave\n========", "1) Go in", "2) Go home", "Inside", "It is dark.", "Safe and sound.", "The End."},
+		},
+		{
+			name:  "invalid choices",
+			input: "3\nzero\n0\n2\n",
+			want:  []string{"choose an option from 1 to 2 or q to quit", "Safe and sound.", "The End."},
+			not:   []string{"Inside"},
+		},
+		{
+			name:  "quit",
+			input: "1\nq\n",
+			want:  []string{"Inside"},
+			not:   []string{"Home", "The End."},
+		},
+		{
+			name:  "input ends",
+			input: "1\n",
+			want:  []string{"Inside"},
+			not:   []string{"The End."},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			if err := cave.Play(strings.NewReader(tt.input), &out); err != nil {
+				t.Fatal(err)
+			}
+			for _, s := range tt.want {
+				if !strings.Contains(out.String(), s) {
+					t.Errorf("output does not contain %q:\n%s", s, out.String())
+				}
+			}
+			for _, s := range tt.not {
+				if strings.Contains(out.String(), s) {
+					t.Errorf("output contains %q:\n%s", s, out.String())
+				}
+			}
+		})
+	}
+}
+
+func TestPlayConditions(t *testing.T) {
+	a := &Adventure{
+		Name: "lamp",
+		Arcs: []Arc{
+			{ID: IntroArc, Title: "Start", Options: []Option{
+				{NextArcText: "Take the lamp", NextArcName: "lit", Effects: []string{"lamp"}},
+				{NextArcText: "Read the map", NextArcName: "treasure", Requires: []string{"lamp"}},
+			}},
+			{ID: "lit", Title: "Lit", Options: []Option{
+				{NextArcText: "Read the map", NextArcName: "treasure", Requires: []string{"lamp"}},
+			}},
+			{ID: "treasure", Title: "Treasure", Story: []string{"Gold!"}},
+		},
+	}
+
+	var out bytes.Buffer
+	if err := a.Play(strings.NewReader("1\n1\n"), &out); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Count(out.String(), "Read the map") != 1 || !strings.Contains(out.String(), "Gold!") {
+		t.Errorf("unexpected output:\n%s", out.String())
+	}
+}
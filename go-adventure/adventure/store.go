@@ -0,0 +1,129 @@
+package adventure
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// DefaultStore is the store used by the package level functions.
+var DefaultStore = NewStore()
+
+// Store holds adventures by their name and implements the Service
+// interface. It is safe for concurrent use.
+type Store struct {
+	mu         sync.RWMutex
+	adventures map[string]*Adventure
+}
+
+var _ Service = (*Store)(nil)
+
+// NewStore returns an empty store.
+func NewStore() *Store {
+	return &Store{adventures: make(map[string]*Adventure)}
+}
+
+// Add adds the adventures to the store, replacing those with the same
+// name, without reading anything from disk.
+func (s *Store) Add(adventures ...*Adventure) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, a := range adventures {
+		s.adventures[a.Name] = a
+	}
+}
+
+// Remove removes the adventure with the given name from the store.
+func (s *Store) Remove(name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.adventures, name)
+}
+
+// Load loads the adventures stored in JSON format from the given paths,
+// each of which is either a directory holding adventure files or a single
+// adventure file. Without paths, the adventures directory in the working
+// directory is used. The name of an adventure is its file name without
+// extension and with dashes replaced by spaces.
+//
+// Adventures which can't be parsed or are invalid are skipped and reported
+// by the returned error after loading all others.
+func (s *Store) Load(paths ...string) error {
+	if len(paths) == 0 {
+		paths = []string{"adventures"}
+	}
+
+	var (
+		loaded []*Adventure
+		errs   []string
+	)
+	for _, p := range paths {
+		files, err := adventureFiles(p)
+		if err != nil {
+			return err
+		}
+		for _, f := range files {
+			a, err := New(NameOf(f), f)
+			if err != nil {
+				errs = append(errs, err.Error())
+				continue
+			}
+			loaded = append(loaded, a)
+		}
+	}
+	s.Add(loaded...)
+
+	if len(errs) > 0 {
+		return fmt.Errorf("error loading adventures: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// RTFV returns the adventure with the given name, nil if there is none.
+func (s *Store) RTFV(name string) *Adventure {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.adventures[name]
+}
+
+// ListAdventureNames returns the names of all adventures in alphabetical
+// order.
+func (s *Store) ListAdventureNames() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	names := make([]string, 0, len(s.adventures))
+	for n := range s.adventures {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// NameOf returns the name of the adventure stored in the file at path.
+func NameOf(path string) string {
+	n := filepath.Base(path)
+	n = n[:len(n)-len(filepath.Ext(n))]
+	return strings.ReplaceAll(n, "-", " ")
+}
+
+// adventureFiles returns the JSON files in the directory at path, or path
+// itself if it is a file.
+func adventureFiles(path string) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return []string{path}, nil
+	}
+
+	files, err := filepath.Glob(filepath.Join(path, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+	return files, nil
+}
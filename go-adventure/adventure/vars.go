@@ -0,0 +1,145 @@
+package adventure
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Vars holds the story variables of a single player, like the items
+// picked up or a counter. An unset variable is zero, and an item is a
+// variable which is set to a positive number once the player has it.
+type Vars map[string]int
+
+// Copy returns a copy of v, which may be changed without changing v.
+func (v Vars) Copy() Vars {
+	c := make(Vars, len(v))
+	for k, n := range v {
+		c[k] = n
+	}
+	return c
+}
+
+// Available reports whether all conditions of the option hold for v.
+// Each condition is one of
+//
+//	lamp        lamp is set, i.e. greater than zero
+//	!lamp       lamp is not set
+//	coins >= 3  coins compares to the number, using ==, !=, <, <=, > or >=
+//
+// Invalid conditions never hold, they are reported by Validate.
+func (o Option) Available(v Vars) bool {
+	for _, c := range o.Requires {
+		ok, err := condition(c, v)
+		if err != nil || !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// Apply applies all effects of the option to v. Each effect is one of
+//
+//	lamp        sets lamp to one, like picking up an item
+//	!lamp       sets lamp to zero, like dropping an item
+//	coins = 3   sets coins to the number
+//	coins += 2  adds the number to coins, -= subtracts it
+//
+// Invalid effects are skipped, they are reported by Validate.
+func (o Option) Apply(v Vars) {
+	for _, e := range o.Effects {
+		effect(e, v)
+	}
+}
+
+// Available returns the options of the arc available for v.
+func (arc Arc) Available(v Vars) []Option {
+	var options []Option
+	for _, o := range arc.Options {
+		if o.Available(v) {
+			options = append(options, o)
+		}
+	}
+	return options
+}
+
+// condition evaluates the condition c for v.
+func condition(c string, v Vars) (bool, error) {
+	name, op, n, err := parseExpr(c)
+	if err != nil {
+		return false, err
+	}
+	x := v[name]
+	switch op {
+	case "":
+		return x > 0, nil
+	case "!":
+		return x <= 0, nil
+	case "==":
+		return x == n, nil
+	case "!=":
+		return x != n, nil
+	case "<":
+		return x < n, nil
+	case "<=":
+		return x <= n, nil
+	case ">":
+		return x > n, nil
+	case ">=":
+		return x >= n, nil
+	}
+	return false, fmt.Errorf("invalid condition %q", c)
+}
+
+// effect applies the effect e to v.
+func effect(e string, v Vars) error {
+	name, op, n, err := parseExpr(e)
+	if err != nil {
+		return err
+	}
+	switch op {
+	case "":
+		v[name] = 1
+	case "!":
+		v[name] = 0
+	case "=":
+		v[name] = n
+	case "+=":
+		v[name] += n
+	case "-=":
+		v[name] -= n
+	default:
+		return fmt.Errorf("invalid effect %q", e)
+	}
+	return nil
+}
+
+// operators are the operators known by conditions and effects, longer
+// ones first so that >= isn't taken for >.
+var operators = []string{"==", "!=", "<=", ">=", "+=", "-=", "<", ">", "="}
+
+// parseExpr splits a condition or effect into the name of the variable,
+// the operator and the number. The operator is empty or ! for expressions
+// made up of the name only.
+func parseExpr(s string) (name, op string, n int, err error) {
+	s = strings.TrimSpace(s)
+	for _, o := range operators {
+		if i := strings.Index(s, o); i >= 0 {
+			name, op = strings.TrimSpace(s[:i]), o
+			if n, err = strconv.Atoi(strings.TrimSpace(s[i+len(o):])); err != nil {
+				return "", "", 0, fmt.Errorf("invalid number in %q", s)
+			}
+			break
+		}
+	}
+	if op == "" {
+		name = s
+		if strings.HasPrefix(name, "!") {
+			name, op = strings.TrimSpace(name[1:]), "!"
+		}
+	}
+	if name == "" || strings.ContainsAny(name, " !") {
+		return "", "", 0, fmt.Errorf("invalid variable in %q", s)
+	}
+	return name, op, n, nil
+}
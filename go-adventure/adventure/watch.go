@@ -0,0 +1,143 @@
+package adventure
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Watcher reloads the adventures of a store whenever their files change,
+// so they can be edited while being served. Files are polled for changes
+// of their modification time or size. A changed adventure is swapped in
+// as a whole, one which fails to parse or validate is reported and the
+// last good version is kept. Adventures whose file is removed are removed
+// from the store as well, once the file is missing in two polls in a row,
+// so an editor replacing the file doesn't take the adventure offline.
+type Watcher struct {
+	Store *Store
+
+	// Paths are the directories or files watched, like the paths of
+	// Store.Load.
+	Paths []string
+
+	// Interval is the time between polls, one second if zero.
+	Interval time.Duration
+
+	// Changed is called with the name of every adventure reloaded or
+	// removed, and Error with every error encountered, both may be nil.
+	Changed func(name string)
+	Error   func(err error)
+
+	// stamps remembers the state of every file seen in the last poll and
+	// missing the files which were missing in it, but not before.
+	stamps  map[string]stamp
+	missing map[string]bool
+}
+
+// stamp is the state of a file used to detect changes.
+type stamp struct {
+	mod  time.Time
+	size int64
+}
+
+// Run watches the files until ctx is done. Files which exist when Run is
+// called are taken to be loaded already.
+func (w *Watcher) Run(ctx context.Context) error {
+	interval := w.Interval
+	if interval <= 0 {
+		interval = time.Second
+	}
+
+	w.stamps, _ = w.scan()
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-t.C:
+			w.Poll()
+		}
+	}
+}
+
+// Poll checks all files once and reloads the changed ones.
+func (w *Watcher) Poll() {
+	stamps, failed := w.scan()
+	for f, st := range stamps {
+		if old, ok := w.stamps[f]; ok && old == st {
+			continue
+		}
+		a, err := New(NameOf(f), f)
+		if err != nil {
+			w.error(err)
+			continue
+		}
+		w.Store.Add(a)
+		if w.Changed != nil {
+			w.Changed(a.Name)
+		}
+	}
+
+	// files which couldn't be checked are kept as they are, and missing
+	// ones only removed if they were missing before as well
+	missing := make(map[string]bool)
+	for f, st := range w.stamps {
+		if _, ok := stamps[f]; ok {
+			continue
+		}
+		unchecked := failed[f] || failed[filepath.Dir(f)]
+		if unchecked || !w.missing[f] {
+			stamps[f] = st
+			missing[f] = !unchecked
+			continue
+		}
+		w.Store.Remove(NameOf(f))
+		if w.Changed != nil {
+			w.Changed(NameOf(f))
+		}
+	}
+	w.stamps, w.missing = stamps, missing
+}
+
+// scan returns the current state of all watched files, leaving out those
+// which don't exist. failed holds the paths and files which couldn't be
+// checked for another reason.
+func (w *Watcher) scan() (stamps map[string]stamp, failed map[string]bool) {
+	paths := w.Paths
+	if len(paths) == 0 {
+		paths = []string{"adventures"}
+	}
+
+	stamps, failed = make(map[string]stamp), make(map[string]bool)
+	for _, p := range paths {
+		p = filepath.Clean(p)
+		files, err := adventureFiles(p)
+		if err != nil {
+			if !os.IsNotExist(err) {
+				w.error(err)
+				failed[p] = true
+			}
+			continue
+		}
+		for _, f := range files {
+			info, err := os.Stat(f)
+			if err != nil {
+				if !os.IsNotExist(err) {
+					failed[f] = true
+				}
+				continue
+			}
+			stamps[f] = stamp{mod: info.ModTime(), size: info.Size()}
+		}
+	}
+	return stamps, failed
+}
+
+// error reports err if anyone is interested.
+func (w *Watcher) error(err error) {
+	if w.Error != nil {
+		w.Error(err)
+	}
+}
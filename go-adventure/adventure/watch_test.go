@@ -0,0 +1,85 @@
+package adventure
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeAdventure writes an adventure with a single arc of the given title
+// to path, replacing the file by renaming like many editors do.
+func writeAdventure(t *testing.T, path, title string) {
+	t.Helper()
+	tmp := path + ".tmp"
+	content := `{"intro": {"title": "` + title + `", "story": ["Once upon a time."]}}`
+	if err := ioutil.WriteFile(tmp, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// title returns the title of the intro of the adventure in s, empty if
+// the adventure is not in s.
+func title(s *Store, name string) string {
+	a := s.RTFV(name)
+	if a == nil {
+		return ""
+	}
+	arc, _ := a.Arc(IntroArc)
+	return arc.Title
+}
+
+func TestWatcher(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "red-gopher.json")
+	writeAdventure(t, path, "Red")
+
+	s := NewStore()
+	w := &Watcher{Store: s, Paths: []string{dir}}
+	w.Poll()
+	if got := title(s, "red gopher"); got != "Red" {
+		t.Fatalf("got %q, want the adventure to be loaded", got)
+	}
+
+	writeAdventure(t, path, "Crimson")
+	w.Poll()
+	if got := title(s, "red gopher"); got != "Crimson" {
+		t.Errorf("got %q after change, want Crimson", got)
+	}
+
+	// an invalid version keeps the last good one
+	if err := ioutil.WriteFile(path, []byte(`{"intro": {"title": "x", "options": [{"arc": "nowhere"}]}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	w.Poll()
+	if got := title(s, "red gopher"); got != "Crimson" {
+		t.Errorf("got %q after invalid change, want Crimson", got)
+	}
+
+	// a file missing once is kept, it may be about to be written again
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	w.Poll()
+	if got := title(s, "red gopher"); got != "Crimson" {
+		t.Errorf("got %q after file went missing, want Crimson", got)
+	}
+	writeAdventure(t, path, "Scarlet")
+	w.Poll()
+	if got := title(s, "red gopher"); got != "Scarlet" {
+		t.Errorf("got %q after file came back, want Scarlet", got)
+	}
+
+	// once it is missing twice, it is gone
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	w.Poll()
+	w.Poll()
+	if got := title(s, "red gopher"); got != "" {
+		t.Errorf("got %q after removal, want the adventure to be removed", got)
+	}
+}
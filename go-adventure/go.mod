@@ -1,3 +1,5 @@
 module github.com/mbraunwarth/adventure
 
 go 1.15
+
+require go.etcd.io/bbolt v1.3.6
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/mbraunwarth/adventure/adventure"
+)
+
+// graph writes the graph of the adventure named by the first argument,
+// which may also be the path of an adventure file, to stdout and a summary
+// of dead ends, unreachable arcs and cycles to stderr. The adventure is
+// not validated, so broken adventures can be examined as well.
+func graph(args []string) {
+	fs := flag.NewFlagSet("graph", flag.ExitOnError)
+	dir := fs.String("dir", "adventures", "directory to load adventures from")
+	format := fs.String("format", "dot", "output format, dot or mermaid")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: %s graph [flags] <name>\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	path := fs.Arg(0)
+	if filepath.Ext(path) != ".json" {
+		path = filepath.Join(*dir, strings.ReplaceAll(path, " ", "-")+".json")
+	}
+	a, err := adventure.Read(adventure.NameOf(path), path)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	switch *format {
+	case "dot":
+		err = a.WriteDOT(os.Stdout)
+	case "mermaid":
+		err = a.WriteMermaid(os.Stdout)
+	default:
+		err = fmt.Errorf("unknown format %q, use dot or mermaid", *format)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	an := a.Analyze()
+	fmt.Fprintf(os.Stderr, "dead ends: %s\n", list(an.DeadEnds))
+	fmt.Fprintf(os.Stderr, "unreachable: %s\n", list(an.Unreachable))
+	for _, c := range an.Cycles {
+		fmt.Fprintf(os.Stderr, "cycle: %s\n", list(c))
+	}
+}
+
+// list joins the IDs of arcs for the summary.
+func list(ids []string) string {
+	if len(ids) == 0 {
+		return "none"
+	}
+	return strings.Join(ids, ", ")
+}
@@ -0,0 +1,239 @@
+package http
+
+import (
+	"encoding/json"
+	"log"
+	"mime"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/mbraunwarth/adventure/adventure"
+)
+
+// APIHandler serves the adventures read-only as JSON:
+//
+//	GET /api/adventures                       all adventures
+//	GET /api/adventures/{adventure}           an adventure and its arcs
+//	GET /api/adventures/{adventure}/arcs/{id} a single arc
+//
+// Adventures are named by their slug, see Slug. Errors are reported as
+// an object with the keys error and status. Requests which don't accept
+// JSON are answered with 406 Not Acceptable.
+type APIHandler struct {
+	s adventure.Service
+}
+
+// NewAPIHandler returns an API handler serving the adventures of s. The
+// zero APIHandler serves the adventures of the adventure.DefaultStore.
+func NewAPIHandler(s adventure.Service) APIHandler {
+	return APIHandler{s: s}
+}
+
+// apiPrefix is the path the API is served at.
+const apiPrefix = "/api/adventures"
+
+// adventureSummary is an adventure as listed.
+type adventureSummary struct {
+	Name string `json:"name"`
+	Slug string `json:"slug"`
+	URL  string `json:"url"`
+}
+
+// adventureResponse is a single adventure.
+type adventureResponse struct {
+	adventureSummary
+	Intro string       `json:"intro"`
+	Arcs  []arcSummary `json:"arcs"`
+}
+
+// arcSummary is an arc as listed.
+type arcSummary struct {
+	ID    string `json:"id"`
+	Title string `json:"title"`
+	URL   string `json:"url"`
+}
+
+// arcResponse is a single arc.
+type arcResponse struct {
+	ID      string           `json:"id"`
+	Title   string           `json:"title"`
+	Story   []string         `json:"story"`
+	Options []optionResponse `json:"options"`
+}
+
+// optionResponse is an option of an arc.
+type optionResponse struct {
+	adventure.Option
+	URL string `json:"url"`
+}
+
+// ServeHTTP implements the http.Handler interface.
+func (h APIHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Vary", "Accept")
+	if !acceptsJSON(r.Header.Get("Accept")) {
+		apiError(w, http.StatusNotAcceptable, "only application/json is available")
+		return
+	}
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		apiError(w, http.StatusMethodNotAllowed, "method "+r.Method+" not allowed")
+		return
+	}
+
+	// split the path after the prefix into adventure, "arcs" and arc, each
+	// of which may hold escaped slashes
+	path := r.URL.EscapedPath()
+	rest := strings.TrimPrefix(path, apiPrefix)
+	if rest == path || rest != "" && rest[0] != '/' {
+		apiError(w, http.StatusNotFound, "no such resource")
+		return
+	}
+	var parts []string
+	if rest = strings.Trim(rest, "/"); rest != "" {
+		parts = strings.Split(rest, "/")
+	}
+	for i, p := range parts {
+		var err error
+		if parts[i], err = url.PathUnescape(p); err != nil {
+			apiError(w, http.StatusBadRequest, "invalid path")
+			return
+		}
+	}
+
+	switch {
+	case len(parts) == 0:
+		h.list(w)
+	case len(parts) == 1:
+		h.adventure(w, parts[0])
+	case len(parts) == 3 && parts[1] == "arcs":
+		h.arc(w, parts[0], parts[2])
+	default:
+		apiError(w, http.StatusNotFound, "no such resource")
+	}
+}
+
+// service returns the service adventures are served from.
+func (h APIHandler) service() adventure.Service {
+	if h.s == nil {
+		return adventure.DefaultStore
+	}
+	return h.s
+}
+
+// list responds with all adventures.
+func (h APIHandler) list(w http.ResponseWriter) {
+	adventures := make([]adventureSummary, 0)
+	for _, n := range h.service().ListAdventureNames() {
+		adventures = append(adventures, summaryOf(n))
+	}
+	writeJSON(w, http.StatusOK, struct {
+		Adventures []adventureSummary `json:"adventures"`
+	}{adventures})
+}
+
+// adventure responds with the adventure with the given slug.
+func (h APIHandler) adventure(w http.ResponseWriter, slug string) {
+	adv := h.service().RTFV(Unslug(slug))
+	if adv == nil {
+		apiError(w, http.StatusNotFound, "no adventure "+strconv.Quote(Unslug(slug)))
+		return
+	}
+
+	res := adventureResponse{
+		adventureSummary: summaryOf(adv.Name),
+		Intro:            adventure.IntroArc,
+		Arcs:             make([]arcSummary, 0, len(adv.Arcs)),
+	}
+	for _, arc := range adv.Arcs {
+		res.Arcs = append(res.Arcs, arcSummary{
+			ID:    arc.ID,
+			Title: arc.Title,
+			URL:   arcURL(adv.Name, arc.ID),
+		})
+	}
+	writeJSON(w, http.StatusOK, res)
+}
+
+// arc responds with the arc with the given ID of the adventure with the
+// given slug.
+func (h APIHandler) arc(w http.ResponseWriter, slug, id string) {
+	adv := h.service().RTFV(Unslug(slug))
+	if adv == nil {
+		apiError(w, http.StatusNotFound, "no adventure "+strconv.Quote(Unslug(slug)))
+		return
+	}
+	arc, ok := adv.Arc(id)
+	if !ok {
+		apiError(w, http.StatusNotFound, "no arc "+strconv.Quote(id)+" in adventure "+strconv.Quote(adv.Name))
+		return
+	}
+
+	res := arcResponse{
+		ID:      arc.ID,
+		Title:   arc.Title,
+		Story:   arc.Story,
+		Options: make([]optionResponse, 0, len(arc.Options)),
+	}
+	if res.Story == nil {
+		res.Story = []string{}
+	}
+	for _, o := range arc.Options {
+		res.Options = append(res.Options, optionResponse{Option: o, URL: arcURL(adv.Name, o.NextArcName)})
+	}
+	writeJSON(w, http.StatusOK, res)
+}
+
+// summaryOf returns the summary of the adventure with the given name.
+func summaryOf(name string) adventureSummary {
+	return adventureSummary{Name: name, Slug: Slug(name), URL: apiPrefix + "/" + url.PathEscape(Slug(name))}
+}
+
+// arcURL returns the URL of an arc of the adventure with the given name.
+func arcURL(name, id string) string {
+	return apiPrefix + "/" + url.PathEscape(Slug(name)) + "/arcs/" + url.PathEscape(id)
+}
+
+// acceptsJSON reports whether a client sending the given Accept header
+// accepts JSON, which is the case without header.
+func acceptsJSON(accept string) bool {
+	if strings.TrimSpace(accept) == "" {
+		return true
+	}
+	for _, rng := range strings.Split(accept, ",") {
+		typ, params, err := mime.ParseMediaType(strings.TrimSpace(rng))
+		if err != nil {
+			continue
+		}
+		if q, err := strconv.ParseFloat(params["q"], 64); err == nil && q <= 0 {
+			continue
+		}
+		switch typ {
+		case "application/json", "application/*", "*/*":
+			return true
+		}
+	}
+	return false
+}
+
+// apiError responds with an error object.
+func apiError(w http.ResponseWriter, code int, msg string) {
+	writeJSON(w, code, struct {
+		Error  string `json:"error"`
+		Status int    `json:"status"`
+	}{msg, code})
+}
+
+// writeJSON responds with v encoded as JSON.
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	b, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		log.Print(err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(code)
+	w.Write(append(b, '\n'))
+}
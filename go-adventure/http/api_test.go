@@ -0,0 +1,111 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mbraunwarth/adventure/adventure"
+)
+
+// newAPI returns a test server for an API serving a single adventure,
+// which has an arc whose ID needs escaping.
+func newAPI(t *testing.T) *httptest.Server {
+	t.Helper()
+	s := adventure.NewStore()
+	s.Add(&adventure.Adventure{
+		Name: "odd gopher",
+		Arcs: []adventure.Arc{
+			{ID: adventure.IntroArc, Title: "Intro", Options: []adventure.Option{
+				{NextArcText: "Onwards", NextArcName: "a/b ?#c"},
+			}},
+			{ID: "a/b ?#c", Title: "Odd"},
+		},
+	})
+	ts := httptest.NewServer(NewAPIHandler(s))
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+// get fetches url and decodes the JSON response into v, failing the test
+// unless the status is code.
+func get(t *testing.T, url string, code int, v interface{}) {
+	t.Helper()
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != code {
+		t.Fatalf("GET %s: got %s, want %d", url, resp.Status, code)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		t.Fatalf("GET %s: %v", url, err)
+	}
+}
+
+func TestAPIEscapesURLs(t *testing.T) {
+	ts := newAPI(t)
+
+	var list struct {
+		Adventures []adventureSummary `json:"adventures"`
+	}
+	get(t, ts.URL+"/api/adventures", http.StatusOK, &list)
+	if len(list.Adventures) != 1 || list.Adventures[0].URL != "/api/adventures/odd-gopher" {
+		t.Fatalf("got %+v", list.Adventures)
+	}
+
+	var adv adventureResponse
+	get(t, ts.URL+list.Adventures[0].URL, http.StatusOK, &adv)
+	if len(adv.Arcs) != 2 {
+		t.Fatalf("got %d arcs, want 2", len(adv.Arcs))
+	}
+	want := "/api/adventures/odd-gopher/arcs/a%2Fb%20%3F%23c"
+	if adv.Arcs[1].URL != want {
+		t.Errorf("got arc URL %s, want %s", adv.Arcs[1].URL, want)
+	}
+
+	// the URLs of the intro's option and of the arc itself lead to the arc
+	var intro arcResponse
+	get(t, ts.URL+adv.Arcs[0].URL, http.StatusOK, &intro)
+	if len(intro.Options) != 1 || intro.Options[0].URL != want {
+		t.Fatalf("got options %+v", intro.Options)
+	}
+	var arc arcResponse
+	get(t, ts.URL+intro.Options[0].URL, http.StatusOK, &arc)
+	if arc.ID != "a/b ?#c" || arc.Title != "Odd" {
+		t.Errorf("got arc %+v", arc)
+	}
+}
+
+func TestAPIErrors(t *testing.T) {
+	ts := newAPI(t)
+
+	for _, path := range []string{
+		"/api/adventures/nope",
+		"/api/adventures/odd-gopher/arcs/nope",
+		"/api/adventures/odd-gopher/arcs/a/b",
+		"/api/adventuresfoo",
+	} {
+		var e struct {
+			Error  string `json:"error"`
+			Status int    `json:"status"`
+		}
+		get(t, ts.URL+path, http.StatusNotFound, &e)
+		if e.Status != http.StatusNotFound || e.Error == "" {
+			t.Errorf("%s: got %+v", path, e)
+		}
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, ts.URL+"/api/adventures", nil)
+	req.Header.Set("Accept", "text/html")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotAcceptable {
+		t.Errorf("got %s for text/html, want 406", resp.Status)
+	}
+}
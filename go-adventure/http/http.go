@@ -1,50 +1,357 @@
 package http
 
 import (
+	"bytes"
+	"errors"
+	"fmt"
 	"html/template"
 	"log"
 	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
 
 	"github.com/mbraunwarth/adventure/adventure"
+	"github.com/mbraunwarth/adventure/session"
 )
 
-// Handler wraps the HTTP handler for adventures.
+// Handler wraps the HTTP handler for adventures. It serves the list of
+// all adventures at /, the intro of an adventure at /{adventure} and any
+// other arc at /{adventure}/{arc}, where the adventure is named by its
+// slug, see Slug.
+//
+// Every player is identified by a cookie, set on the first post, and the
+// path taken through each adventure is stored in a session store, so
+// /{adventure} continues where the player left off. Only the current arc is shown, any other one
+// redirects to it. The game only changes by posting to /{adventure}, so
+// following a link never does: action=choose with from set to the current
+// arc and option to the index of one of its options moves on, while
+// action=back and action=restart go back one arc or start over.
 type Handler struct {
-	s adventure.Service
+	s        adventure.Service
+	sessions session.Store
+}
+
+// cookieName is the name of the cookie holding the player ID.
+const cookieName = "player"
+
+// defaultSessions is the session store of the zero Handler.
+var defaultSessions = session.NewMemoryStore()
+
+// NewHandler returns a handler serving the adventures of s and keeping
+// players in sessions. The zero Handler serves the adventures of the
+// adventure.DefaultStore and keeps players in memory.
+func NewHandler(s adventure.Service, sessions session.Store) Handler {
+	return Handler{s: s, sessions: sessions}
+}
+
+// service returns the service adventures are served from.
+func (h Handler) service() adventure.Service {
+	if h.s == nil {
+		return adventure.DefaultStore
+	}
+	return h.s
+}
+
+// store returns the store players are kept in.
+func (h Handler) store() session.Store {
+	if h.sessions == nil {
+		return defaultSessions
+	}
+	return h.sessions
 }
 
 // ServeHTTP function for Handler.
 func (h Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	n := "blue gopher"
-	adv := adventure.RTFV(n)
+	// split path into adventure and arc, both of which may be empty and
+	// may hold escaped slashes
+	parts := strings.Split(strings.Trim(r.URL.EscapedPath(), "/"), "/")
+	if len(parts) > 2 {
+		notFound(w, "There is no such page.")
+		return
+	}
+	for i, p := range parts {
+		var err error
+		if parts[i], err = url.PathUnescape(p); err != nil {
+			http.Error(w, "invalid path", http.StatusBadRequest)
+			return
+		}
+	}
+
+	read := r.Method == http.MethodGet || r.Method == http.MethodHead
+	if parts[0] == "" {
+		if !read {
+			notAllowed(w, "GET, HEAD")
+			return
+		}
+		h.list(w)
+		return
+	}
+
+	adv := h.service().RTFV(Unslug(parts[0]))
+	if adv == nil {
+		notFound(w, "There is no adventure called "+Unslug(parts[0])+".")
+		return
+	}
+	id := ""
+	if len(parts) == 2 {
+		id = parts[1]
+	}
+
+	switch {
+	case read:
+		h.arc(w, r, adv, id)
+	case r.Method == http.MethodPost && id == "":
+		h.act(w, r, adv)
+	case id == "":
+		notAllowed(w, "GET, HEAD, POST")
+	default:
+		notAllowed(w, "GET, HEAD")
+	}
+}
+
+// arc renders the current arc of the player, redirecting there if the
+// arc with the given ID is another one. Players who haven't played yet
+// see the intro, they are only stored once they choose an option.
+func (h Handler) arc(w http.ResponseWriter, r *http.Request, adv *adventure.Adventure, id string) {
+	p, err := h.load(r)
+	if err != nil {
+		internalError(w, err)
+		return
+	}
+	g := &session.Game{Path: []string{adventure.IntroArc}}
+	if p != nil {
+		if _, ok := p.Games[adv.Name]; ok {
+			g = p.Game(adv.Name, adventure.IntroArc)
+		}
+	}
+
+	// the story may have changed since the player was here last
+	arc, ok := adv.Arc(g.Current())
+	if !ok {
+		g.Restart(adventure.IntroArc)
+		arc, _ = adv.Arc(adventure.IntroArc)
+		if p != nil {
+			if err := h.store().Save(p); err != nil {
+				internalError(w, err)
+				return
+			}
+		}
+	}
 
-	var arc adventure.Arc
-	for _, a := range adv.Arcs {
-		if a.ID == "new-york" {
-			arc = a
+	if id != "" && id != arc.ID {
+		if _, ok := adv.Arc(id); !ok {
+			notFound(w, "The "+adv.Name+" adventure has no arc called "+id+".")
+			return
+		}
+		// no skipping around, show where the player actually is
+		http.Redirect(w, r, arcPath(adv.Name, arc.ID), http.StatusSeeOther)
+		return
+	}
+
+	var path []string
+	for _, id := range g.Path {
+		if a, ok := adv.Arc(id); ok {
+			path = append(path, a.Title)
+		}
+	}
 
+	// options are chosen by their index among all options of the arc
+	type choice struct {
+		Index int
+		Text  string
+	}
+	var choices []choice
+	for i, o := range arc.Options {
+		if o.Available(g.Variables()) {
+			choices = append(choices, choice{Index: i, Text: o.NextArcText})
 		}
 	}
 
 	data := struct {
 		Adventure string
+		Slug      string
+		ID        string
 		Arc       string
 		Story     []string
-		Options   []adventure.Option
+		Options   []choice
+		Path      []string
+		Inventory []string
 	}{
 		Adventure: adv.Name,
+		Slug:      url.PathEscape(Slug(adv.Name)),
+		ID:        arc.ID,
 		Arc:       arc.Title,
 		Story:     arc.Story,
-		Options:   arc.Options,
+		Options:   choices,
+		Path:      path,
+		Inventory: inventory(g.Variables()),
 	}
+	render(w, http.StatusOK, "home.html", data)
+}
 
-	t, err := template.New("home.html").ParseFiles("tmpl/home.html")
+// inventory lists the variables which are set, with their value if it is
+// more than one, like "lamp" or "coins (3)".
+func inventory(vars map[string]int) []string {
+	var items []string
+	for k, n := range vars {
+		switch {
+		case n == 1:
+			items = append(items, k)
+		case n > 1:
+			items = append(items, fmt.Sprintf("%s (%d)", k, n))
+		}
+	}
+	sort.Strings(items)
+	return items
+}
+
+// choose moves the game on along the option with the given index of the
+// arc with the given ID, applying its effects. It does nothing unless the
+// arc is the current one and the option is available, e.g. if the option
+// was chosen from a stale page.
+func choose(adv *adventure.Adventure, g *session.Game, from, index string) {
+	cur, ok := adv.Arc(g.Current())
+	i, err := strconv.Atoi(index)
+	if !ok || cur.ID != from || err != nil || i < 0 || i >= len(cur.Options) {
+		return
+	}
+	vars := adventure.Vars(g.Variables())
+	if o := cur.Options[i]; o.Available(vars) {
+		o.Apply(vars)
+		g.Choose(o.NextArcName, vars)
+	}
+}
+
+// act performs the action posted by the player and redirects to the arc
+// the player is at afterwards.
+func (h Handler) act(w http.ResponseWriter, r *http.Request, adv *adventure.Adventure) {
+	p, err := h.player(w, r)
+	if err != nil {
+		internalError(w, err)
+		return
+	}
+	g := p.Game(adv.Name, adventure.IntroArc)
+
+	switch action := r.PostFormValue("action"); action {
+	case "choose":
+		choose(adv, g, r.PostFormValue("from"), r.PostFormValue("option"))
+	case "back":
+		g.Back()
+	case "restart":
+		g.Restart(adventure.IntroArc)
+	default:
+		http.Error(w, "unknown action "+action, http.StatusBadRequest)
+		return
+	}
+	if err := h.store().Save(p); err != nil {
+		internalError(w, err)
+		return
+	}
+	http.Redirect(w, r, arcPath(adv.Name, g.Current()), http.StatusSeeOther)
+}
+
+// arcPath returns the path of the arc with the given ID of the adventure
+// with the given name.
+func arcPath(name, id string) string {
+	return "/" + url.PathEscape(Slug(name)) + "/" + url.PathEscape(id)
+}
+
+// load returns the stored player of the request, which is nil if the
+// request has no cookie or the player is not stored.
+func (h Handler) load(r *http.Request) (*session.Player, error) {
+	c, err := r.Cookie(cookieName)
 	if err != nil {
-		log.Fatalf("error parsing templates: %s", err)
+		return nil, nil
+	}
+	p, err := h.store().Load(c.Value)
+	if errors.Is(err, session.ErrNotFound) {
+		return nil, nil
 	}
-	if err := t.Execute(w, data); err != nil {
-		log.Fatalf("error executing template: %s", err)
+	return p, err
+}
+
+// player returns the player of the request, which is a new one with a
+// cookie set if the request has no cookie or the player is not stored.
+func (h Handler) player(w http.ResponseWriter, r *http.Request) (*session.Player, error) {
+	if p, err := h.load(r); p != nil || err != nil {
+		return p, err
 	}
 
-	//fmt.Fprintf(w, "Adventures available: %s", adventure.ListAdventureNames())
+	p, err := session.NewPlayer()
+	if err != nil {
+		return nil, err
+	}
+	http.SetCookie(w, &http.Cookie{
+		Name:     cookieName,
+		Value:    p.ID,
+		Path:     "/",
+		MaxAge:   365 * 24 * 60 * 60,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+	return p, nil
+}
+
+// list renders the list of all adventures.
+func (h Handler) list(w http.ResponseWriter) {
+	type entry struct {
+		Name string
+		Slug string
+	}
+	var entries []entry
+	for _, n := range h.service().ListAdventureNames() {
+		entries = append(entries, entry{Name: n, Slug: url.PathEscape(Slug(n))})
+	}
+	render(w, http.StatusOK, "list.html", entries)
+}
+
+// Slug returns the path segment of the adventure with the given name, which
+// is the name of its file without extension.
+func Slug(name string) string {
+	return strings.ReplaceAll(name, " ", "-")
+}
+
+// Unslug returns the name of the adventure with the given slug.
+func Unslug(slug string) string {
+	return strings.ReplaceAll(slug, "-", " ")
+}
+
+// notAllowed responds that the method is not allowed.
+func notAllowed(w http.ResponseWriter, allow string) {
+	w.Header().Set("Allow", allow)
+	http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+}
+
+// internalError logs err and responds with an internal server error.
+func internalError(w http.ResponseWriter, err error) {
+	log.Print(err)
+	http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+}
+
+// notFound renders the not found page with the given message.
+func notFound(w http.ResponseWriter, msg string) {
+	render(w, http.StatusNotFound, "notfound.html", msg)
+}
+
+// render executes the template with the given name from the tmpl directory
+// and writes the result with the given status code.
+func render(w http.ResponseWriter, code int, name string, data interface{}) {
+	t, err := template.New(name).ParseFiles("tmpl/" + name)
+	if err != nil {
+		internalError(w, fmt.Errorf("error parsing templates: %w", err))
+		return
+	}
+
+	// execute into a buffer first, so a failing template doesn't leave a
+	// half written page behind
+	var b bytes.Buffer
+	if err := t.Execute(&b, data); err != nil {
+		internalError(w, fmt.Errorf("error executing template: %w", err))
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(code)
+	b.WriteTo(w)
 }
@@ -0,0 +1,150 @@
+package http
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/mbraunwarth/adventure/adventure"
+	"github.com/mbraunwarth/adventure/session"
+)
+
+// newSite returns a test server for a handler serving a single adventure,
+// whose arcs have IDs which need escaping.
+func newSite(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	// templates are read from the tmpl directory of the module
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(".."); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+
+	s := adventure.NewStore()
+	s.Add(&adventure.Adventure{
+		Name: "odd gopher",
+		Arcs: []adventure.Arc{
+			{ID: adventure.IntroArc, Title: "Intro", Options: []adventure.Option{
+				{NextArcText: "Spaces", NextArcName: "a b?c"},
+				{NextArcText: "Slashes", NextArcName: "x/y"},
+			}},
+			{ID: "a b?c", Title: "Spaced out"},
+			{ID: "x/y", Title: "Slashed"},
+		},
+	})
+	ts := httptest.NewServer(NewHandler(s, session.NewMemoryStore()))
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+// page fetches url with c and returns the path it ended up at and the
+// body, failing the test unless the status is OK.
+func page(t *testing.T, c *http.Client, url string) (string, string) {
+	t.Helper()
+	resp, err := c.Get(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return body(t, resp)
+}
+
+// body reads the body of resp, failing the test unless the status is OK.
+func body(t *testing.T, resp *http.Response) (string, string) {
+	t.Helper()
+	defer resp.Body.Close()
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("%s: got %s", resp.Request.URL, resp.Status)
+	}
+	return resp.Request.URL.EscapedPath(), string(b)
+}
+
+func TestChoose(t *testing.T) {
+	ts := newSite(t)
+
+	for _, tc := range []struct {
+		option string
+		path   string
+		title  string
+	}{
+		{"0", "/odd-gopher/a%20b%3Fc", "Spaced out"},
+		{"1", "/odd-gopher/x%2Fy", "Slashed"},
+	} {
+		jar, err := cookiejar.New(nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		c := &http.Client{Jar: jar}
+
+		resp, err := c.PostForm(ts.URL+"/odd-gopher", url.Values{
+			"action": {"choose"},
+			"from":   {adventure.IntroArc},
+			"option": {tc.option},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		path, b := body(t, resp)
+		if path != tc.path || !strings.Contains(b, tc.title) {
+			t.Errorf("option %s: got %s, want %s showing %q", tc.option, path, tc.path, tc.title)
+		}
+
+		// the adventure continues there and other arcs lead back to it
+		if _, b := page(t, c, ts.URL+"/odd-gopher"); !strings.Contains(b, tc.title) {
+			t.Errorf("option %s: the adventure doesn't continue at %q", tc.option, tc.title)
+		}
+		if path, _ := page(t, c, ts.URL+"/odd-gopher/intro"); path != tc.path {
+			t.Errorf("option %s: the intro went to %s, want %s", tc.option, path, tc.path)
+		}
+
+		resp, err = c.PostForm(ts.URL+"/odd-gopher", url.Values{"action": {"back"}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if path, b := body(t, resp); path != "/odd-gopher/intro" || !strings.Contains(b, "Spaces") {
+			t.Errorf("option %s: back went to %s", tc.option, path)
+		}
+	}
+}
+
+func TestReadOnly(t *testing.T) {
+	ts := newSite(t)
+
+	// reading doesn't make a player
+	for _, p := range []string{"/", "/odd-gopher", "/odd-gopher/intro"} {
+		resp, err := http.Get(ts.URL + p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK || len(resp.Cookies()) != 0 {
+			t.Errorf("%s: got %s with cookies %v", p, resp.Status, resp.Cookies())
+		}
+	}
+
+	// without a game all arcs but the intro lead to it
+	c := &http.Client{}
+	if path, _ := page(t, c, ts.URL+"/odd-gopher/x%2Fy"); path != "/odd-gopher/intro" {
+		t.Errorf("got %s, want the intro", path)
+	}
+	resp, err := c.Get(ts.URL + "/odd-gopher/nope")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("got %s for an unknown arc, want 404", resp.Status)
+	}
+}
@@ -1,18 +1,68 @@
 package main
 
 import (
+	"context"
+	"flag"
 	"fmt"
+	"log"
 	"net/http"
+	"os"
 
 	"github.com/mbraunwarth/adventure/adventure"
 	api "github.com/mbraunwarth/adventure/http"
+	"github.com/mbraunwarth/adventure/session"
 )
 
+// go-adventure serves the adventures over HTTP, while `go-adventure play
+// <name>` plays one of them in the terminal and `go-adventure graph <name>`
+// exports its graph of arcs
 func main() {
-	adventure.Load()
-	fmt.Printf("ListAdventures => %s\n", adventure.ListAdventureNames())
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "play":
+			play(os.Args[2:])
+			return
+		case "graph":
+			graph(os.Args[2:])
+			return
+		}
+	}
 
-	var h api.Handler
-	http.Handle("/", h)
-	http.ListenAndServe(":8080", nil)
+	dir := flag.String("dir", "adventures", "directory or file to load adventures from")
+	addr := flag.String("addr", ":8080", "address to listen on")
+	sessions := flag.String("sessions", "", "BoltDB file to save games to, kept in memory if empty")
+	watch := flag.Duration("watch", 0, "reload changed adventures, polling at this interval, 0 disables")
+	flag.Parse()
+
+	s := adventure.NewStore()
+	if err := s.Load(*dir); err != nil {
+		log.Print(err)
+	}
+	fmt.Printf("ListAdventures => %s\n", s.ListAdventureNames())
+
+	if *watch > 0 {
+		w := &adventure.Watcher{
+			Store:    s,
+			Paths:    []string{*dir},
+			Interval: *watch,
+			Changed:  func(name string) { log.Printf("reloaded %s", name) },
+			Error:    func(err error) { log.Print(err) },
+		}
+		go w.Run(context.Background())
+	}
+
+	// games are kept in memory unless saved to a database
+	var st session.Store = session.NewMemoryStore()
+	if *sessions != "" {
+		db, err := session.OpenBolt(*sessions)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer db.Close()
+		st = db
+	}
+
+	http.Handle("/", api.NewHandler(s, st))
+	http.Handle("/api/", api.NewAPIHandler(s))
+	log.Fatal(http.ListenAndServe(*addr, nil))
 }
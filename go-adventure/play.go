@@ -0,0 +1,43 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/mbraunwarth/adventure/adventure"
+)
+
+// play plays the adventure named by the first argument in the terminal,
+// its name may be given with dashes like the file name, e.g. blue-gopher.
+func play(args []string) {
+	fs := flag.NewFlagSet("play", flag.ExitOnError)
+	dir := fs.String("dir", "adventures", "directory or file to load adventures from")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: %s play [flags] <name>\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	// broken adventures only matter if it's the one to play
+	s := adventure.NewStore()
+	err := s.Load(*dir)
+	a := s.RTFV(adventure.NameOf(fs.Arg(0)))
+	if a == nil {
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+		}
+		fmt.Fprintf(os.Stderr, "no adventure %q, available: %s\n", fs.Arg(0), strings.Join(s.ListAdventureNames(), ", "))
+		os.Exit(1)
+	}
+
+	if err := a.Play(os.Stdin, os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,66 @@
+package session
+
+import (
+	"encoding/json"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// playersBucket is the bucket players are stored in.
+var playersBucket = []byte("players")
+
+// BoltStore keeps players in a BoltDB database file.
+type BoltStore struct {
+	db *bolt.DB
+}
+
+// OpenBolt opens the database at path, creating it if needed.
+func OpenBolt(path string) (*BoltStore, error) {
+	db, err := bolt.Open(path, 0644, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, err
+	}
+	if err := db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(playersBucket)
+		return err
+	}); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &BoltStore{db: db}, nil
+}
+
+// Load implements the Store interface.
+func (s *BoltStore) Load(id string) (*Player, error) {
+	var b []byte
+	if err := s.db.View(func(tx *bolt.Tx) error {
+		// the value is only valid during the transaction
+		if v := tx.Bucket(playersBucket).Get([]byte(id)); v != nil {
+			b = append(b, v...)
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	if b == nil {
+		return nil, ErrNotFound
+	}
+	return decode(b)
+}
+
+// Save implements the Store interface.
+func (s *BoltStore) Save(p *Player) error {
+	b, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(playersBucket).Put([]byte(p.ID), b)
+	})
+}
+
+// Close closes the database.
+func (s *BoltStore) Close() error {
+	return s.db.Close()
+}
@@ -0,0 +1,51 @@
+package session
+
+import (
+	"encoding/json"
+	"sync"
+)
+
+// MemoryStore keeps players in memory, they are lost once the program
+// exits.
+type MemoryStore struct {
+	mu      sync.Mutex
+	players map[string][]byte
+}
+
+// NewMemoryStore returns an empty memory store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{players: make(map[string][]byte)}
+}
+
+// Load implements the Store interface.
+func (s *MemoryStore) Load(id string) (*Player, error) {
+	s.mu.Lock()
+	b, ok := s.players[id]
+	s.mu.Unlock()
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return decode(b)
+}
+
+// Save implements the Store interface.
+func (s *MemoryStore) Save(p *Player) error {
+	// store the encoded player, so changes to p don't leak into the store
+	b, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.players[p.ID] = b
+	return nil
+}
+
+// decode decodes a player stored as JSON.
+func decode(b []byte) (*Player, error) {
+	var p Player
+	if err := json.Unmarshal(b, &p); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
@@ -0,0 +1,118 @@
+// Package session keeps track of where players are in their adventures,
+// so they can go back and resume playing later.
+package session
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"time"
+)
+
+// ErrNotFound is returned by a Store for unknown players.
+var ErrNotFound = errors.New("player not found")
+
+// Player is everything known about a single player, identified by a
+// random ID stored in a cookie.
+type Player struct {
+	ID    string           `json:"id"`
+	Games map[string]*Game `json:"games"`
+}
+
+// NewPlayer returns a new player with a random ID and no games.
+func NewPlayer() (*Player, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return nil, err
+	}
+	return &Player{ID: hex.EncodeToString(b), Games: make(map[string]*Game)}, nil
+}
+
+// Game returns the game of the player in the given adventure, starting a
+// new one at the given arc if there is none.
+func (p *Player) Game(adventure, start string) *Game {
+	if p.Games == nil {
+		p.Games = make(map[string]*Game)
+	}
+	g, ok := p.Games[adventure]
+	if !ok || len(g.Path) == 0 {
+		g = &Game{Path: []string{start}, Updated: time.Now()}
+		p.Games[adventure] = g
+	}
+	return g
+}
+
+// Game is the state of a single adventure played by a player.
+type Game struct {
+	// Path lists the IDs of all arcs visited, the last one being the
+	// current arc.
+	Path []string `json:"path"`
+
+	// Vars lists the story variables on arrival at each arc of Path, so
+	// going back restores them. Games saved before there were variables
+	// have fewer entries, the missing ones are taken as no variables set.
+	Vars    []map[string]int `json:"vars,omitempty"`
+	Updated time.Time        `json:"updated"`
+}
+
+// Current returns the ID of the current arc.
+func (g *Game) Current() string {
+	return g.Path[len(g.Path)-1]
+}
+
+// Variables returns a copy of the story variables at the current arc.
+func (g *Game) Variables() map[string]int {
+	vars := make(map[string]int)
+	if len(g.Vars) == len(g.Path) {
+		for k, n := range g.Vars[len(g.Vars)-1] {
+			vars[k] = n
+		}
+	}
+	return vars
+}
+
+// Choose moves on to the arc with the given ID, where the story variables
+// are vars.
+func (g *Game) Choose(arc string, vars map[string]int) {
+	for len(g.Vars) < len(g.Path) {
+		g.Vars = append(g.Vars, nil)
+	}
+	g.Path = append(g.Path, arc)
+	g.Vars = append(g.Vars, vars)
+	g.Updated = time.Now()
+}
+
+// Back returns to the previous arc, it reports false if there is none.
+func (g *Game) Back() bool {
+	if len(g.Path) < 2 {
+		return false
+	}
+	g.truncate(len(g.Path) - 1)
+	return true
+}
+
+// Restart starts the game over at the given arc.
+func (g *Game) Restart(start string) {
+	g.Path = []string{start}
+	g.Vars = nil
+	g.Updated = time.Now()
+}
+
+// truncate keeps the first n arcs of the path.
+func (g *Game) truncate(n int) {
+	g.Path = g.Path[:n]
+	if len(g.Vars) > n {
+		g.Vars = g.Vars[:n]
+	}
+	g.Updated = time.Now()
+}
+
+// Store saves players so they can resume their games later. A Store must
+// be safe for concurrent use.
+type Store interface {
+	// Load returns the player with the given ID or ErrNotFound.
+	Load(id string) (*Player, error)
+
+	// Save saves the player, replacing any former state.
+	Save(p *Player) error
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"net"
+
+	"github.com/mbraunwarth/quiz/multiplayer"
+)
+
+// host runs the quiz for several players connecting over TCP, sharing a
+// live leaderboard which is kept on disk.
+func host(args []string) {
+	fs := flag.NewFlagSet("host", flag.ExitOnError)
+	addr := fs.String("addr", ":4000", "TCP address to listen on")
+	boardPath := fs.String("leaderboard", "leaderboard.json", "JSON file keeping the leaderboard across restarts")
+	quizFlags(fs)
+	fs.Parse(args)
+
+	q, err := newQuiz(fs)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	board, err := multiplayer.OpenLeaderboard(*boardPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	l, err := net.Listen("tcp", *addr)
+	if err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("hosting quiz with %d problems on %s, join with e.g. `nc localhost %s`", q.Bank.Len(), l.Addr(), portOf(l.Addr()))
+	log.Fatal(multiplayer.NewHost(q, board).Serve(l))
+}
+
+// portOf returns the port of a TCP address.
+func portOf(addr net.Addr) string {
+	_, port, err := net.SplitHostPort(addr.String())
+	if err != nil {
+		return addr.String()
+	}
+	return port
+}
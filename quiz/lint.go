@@ -0,0 +1,51 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/mbraunwarth/quiz/quiz"
+)
+
+// lint checks the given problem files, problems.csv if none is given, and
+// prints every finding prefixed by the file and line. It exits with 1 if
+// there are any findings and with 2 if a file can't be read, so it fails
+// a CI build.
+func lint(args []string) {
+	fs := flag.NewFlagSet("lint", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: %s lint [file ...]\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+	quiet := fs.Bool("q", false, "only set the exit status, print nothing")
+	fs.Parse(args)
+
+	paths := fs.Args()
+	if len(paths) == 0 {
+		paths = []string{"problems.csv"}
+	}
+
+	code := 0
+	for _, path := range paths {
+		diags, err := quiz.Lint(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", path, err)
+			code = 2
+			continue
+		}
+		if len(diags) > 0 && code == 0 {
+			code = 1
+		}
+		for _, d := range diags {
+			switch {
+			case *quiet:
+			case d.Line > 0:
+				fmt.Printf("%s:%s\n", path, d)
+			default:
+				fmt.Printf("%s: %s\n", path, d)
+			}
+		}
+	}
+	os.Exit(code)
+}
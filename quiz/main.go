@@ -1,97 +1,370 @@
 package main
 
 import (
-	"encoding/csv"
+	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"log"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
 	"time"
+
+	"github.com/mbraunwarth/quiz/practice"
+	"github.com/mbraunwarth/quiz/quiz"
+	"github.com/mbraunwarth/quiz/remote"
+	"github.com/mbraunwarth/quiz/tui"
 )
 
 var (
-	problemsPath string      // path to problems csv file
-	timerOn      bool        // status of quiz timer
-	timeLimit    uint        // limit for timer in minutes
-	t            *time.Timer // timer to quit the quiz
-
-	problems map[string]string
-	correct  int
-	answered int
+	problemsPath string        // path to problems file
+	timerOn      bool          // status of quiz timer
+	timeLimit    minutes       // time limit for the whole quiz
+	perQuestion  time.Duration // time limit for a single question
+	shuffle      bool          // ask the problems in random order
+	seed         int64         // seed for shuffling the problems
+	match        string        // name of the answer matcher
+	tolerance    float64       // tolerance of the numeric matcher
+	reportPath   string        // file to export the results to
+	generate     string        // operations to generate problems for
+	genRange     string        // range of the operands of generated problems
+	genCount     int           // number of generated problems
+	practiceOn   bool          // favour weak problems by spaced repetition
+	user         string        // user whose practice history is used
+	resumePath   string        // checkpoint to resume an interrupted quiz from
+	checkpoint   string        // file to save a checkpoint to on interrupt
+	categories   string        // only ask problems of these categories
+	difficulties string        // only ask problems of these difficulties
+	cacheDir     string        // cache directory for remote problem files
+	tuiOn        bool          // play in the interactive terminal UI
+	feedback     string        // when answers are judged in the terminal UI
+
+	// resumed is the checkpoint loaded from resumePath, if any
+	resumed *quiz.Checkpoint
 )
 
 // user can customize the problems file via cli flag
 // if not set by the user, it defaults to problems.csv
 // a pair of a question and its corresponding answer is furthermore referred to as a problem
+//
+// besides playing in the terminal, `quiz serve` serves the quiz over HTTP
+// and `quiz host` lets several players compete over TCP, while
+// `quiz lint` checks problem files for mistakes
 func main() {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "serve":
+			serve(os.Args[2:])
+			return
+		case "host":
+			host(os.Args[2:])
+			return
+		case "lint":
+			lint(os.Args[2:])
+			return
+		}
+	}
+
 	// parse flags and determine the problems file
-	flag.StringVar(&problemsPath, "problems", "problems.csv", "CSV file with problems and their answeres separated by commas")
-	flag.BoolVar(&timerOn, "timer", false, "activate the timer, default time is set to 1 minute")
-	flag.UintVar(&timeLimit, "limit", 1, "set a time limit in minutes for the quiz")
+	quizFlags(flag.CommandLine)
+	flag.StringVar(&reportPath, "report", "", "export a detailed report to a .json or .csv file")
+	flag.BoolVar(&practiceOn, "practice", false, "spaced repetition practice, asking weak and due problems first")
+	flag.StringVar(&user, "user", os.Getenv("USER"), "user whose history is used with -practice")
+	flag.StringVar(&resumePath, "resume", "", "resume an interrupted quiz from its checkpoint file")
+	flag.StringVar(&checkpoint, "checkpoint", "quiz.checkpoint.json", "file to save a checkpoint to when the quiz is interrupted")
+	flag.BoolVar(&tuiOn, "tui", false, "interactive terminal UI with live countdown, plain mode is used if stdout is no terminal")
+	flag.StringVar(&feedback, "feedback", "deferred", "when answers are judged in the terminal UI: immediate or deferred")
 	flag.Parse()
 
-	// if timer is set parse the set duration and initialize time.Timer
-	if timerOn {
-		d, err := time.ParseDuration(fmt.Sprintf("%dm", timeLimit))
-		if err != nil {
-			log.Fatalf("error parsing duration: %s", err)
-		}
-		t = time.AfterFunc(d, func() {
-			fmt.Println("TIMER EXPIRED!!")
-			endQuiz()
-		})
+	fb, err := tui.ParseFeedback(feedback)
+	if err != nil {
+		log.Fatal(err)
 	}
-	defer t.Stop()
+	tuiOn = tuiOn && isTerminal(os.Stdout)
 
-	// preparing csv file for reading
-	problemsFile, err := os.Open(problemsPath)
-	if err != nil {
-		log.Fatalf("error opening file: %s", err)
+	// a resumed quiz takes its problems from the checkpoint, so the other
+	// flags selecting and ordering the problems are ignored
+	if resumePath != "" {
+		if resumed, err = quiz.LoadCheckpoint(resumePath); err != nil {
+			log.Fatalf("error loading checkpoint: %s", err)
+		}
 	}
-	defer problemsFile.Close()
 
-	// parse csv content to records variable resulting in a 2D slice
-	r := csv.NewReader(problemsFile)
-	records, err := r.ReadAll()
+	q, err := newQuiz(flag.CommandLine)
 	if err != nil {
-		log.Fatalf("error reading csv: %s", err)
+		log.Fatal(err)
 	}
+	q.In, q.Out = os.Stdin, os.Stdout
+	q.Resume = resumed
 
-	// fill problems map resulting in the form Question -> Answer [String -> String]
-	problems = make(map[string]string, len(records))
-	for _, row := range records {
-		problems[row[0]] = row[1]
+	// the history lives next to the problems file and is reordering the
+	// problems, overriding -shuffle
+	var history *practice.History
+	if practiceOn {
+		if generate != "" {
+			log.Fatal("-practice needs a problems file and can't be used with -generate")
+		}
+		if history, err = practice.Open(practice.PathFor(problemsPath)); err != nil {
+			log.Fatalf("error reading practice history: %s", err)
+		}
+		if resumed == nil {
+			q.Bank = history.Schedule(user, q.Bank, time.Now())
+		}
 	}
 
-	// for each question store if the user answered correct
-	correct = 0
+	// Ctrl-C or a SIGTERM stop the quiz cleanly with a partial result
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
-	// ask question(s) and take answer from input (single word/number)
+	// ask question(s) and take answer from input (a whole line)
 	// no response to user till all questions has been answered,
 	// but check for correctness and store either right or wrong answered
-	for question, answer := range problems {
-		// resetting user input to empty string
-		input := ""
+	var res *quiz.Result
+	if tuiOn {
+		ui := &tui.UI{Quiz: q, In: os.Stdin, Out: os.Stdout, Feedback: fb}
+		res, err = ui.Run(ctx)
+	} else {
+		res, err = q.RunContext(ctx)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// an interrupted quiz is recorded once it is finished after resuming
+	if history != nil && !res.Interrupted {
+		history.Record(user, res, time.Now())
+		if err := history.Save(); err != nil {
+			log.Fatalf("error saving practice history: %s", err)
+		}
+	}
+	if tuiOn {
+		tui.Summary(os.Stdout, res, fb)
+		writeReport(res)
+	} else {
+		endQuiz(res)
+	}
 
-		fmt.Printf("%s = ", question)
-		if _, err := fmt.Scanln(&input); err != nil {
-			log.Fatalf("error scanning user input: %s", err)
+	if res.Interrupted {
+		saveCheckpoint(q, res)
+	} else if resumePath != "" {
+		// the checkpoint is used up once the quiz is finished
+		os.Remove(resumePath)
+	}
+}
+
+// quizFlags defines the flags configuring the quiz itself on fs, they are
+// shared by all modes.
+func quizFlags(fs *flag.FlagSet) {
+	timeLimit = minutes(time.Minute)
+	fs.StringVar(&problemsPath, "problems", "problems.csv", "CSV, JSON or YAML file or http(s) URL with problems and their answers")
+	fs.StringVar(&cacheDir, "cache", defaultCacheDir(), "cache directory for problem files fetched from a URL")
+	fs.BoolVar(&timerOn, "timer", false, "activate the timer, default time is set to 1 minute")
+	fs.Var(&timeLimit, "limit", "time limit for the whole quiz like 90s or 2m, plain numbers are minutes (implies -timer)")
+	fs.DurationVar(&perQuestion, "per-question", 0, "time limit for each single question like 10s, 0 means no limit")
+	fs.BoolVar(&shuffle, "shuffle", false, "ask the problems in random order instead of file order")
+	fs.Int64Var(&seed, "seed", 0, "seed for -shuffle and -generate to reproduce a session, random if not set")
+	fs.StringVar(&generate, "generate", "", "generate arithmetic problems instead of reading -problems, e.g. add,sub,mul,div")
+	fs.StringVar(&genRange, "range", "1..10", "range of the operands for -generate")
+	fs.IntVar(&genCount, "count", 20, "number of problems for -generate")
+	fs.StringVar(&match, "match", "fold", "how answers are compared: exact, fold (trimmed, case-insensitive) or numeric")
+	fs.Float64Var(&tolerance, "tolerance", 1e-9, "maximum difference for two numbers to be equal with -match numeric")
+	fs.StringVar(&categories, "category", "", "only ask problems of the given comma separated categories")
+	fs.StringVar(&difficulties, "difficulty", "", "only ask problems of the given comma separated difficulties, e.g. hard")
+}
+
+// newQuiz loads the problems and sets up the quiz as configured by the
+// flags defined by quizFlags on the already parsed fs.
+func newQuiz(fs *flag.FlagSet) (*quiz.Quiz, error) {
+	// setting a limit explicitly turns the timer on as well
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == "limit" {
+			timerOn = true
 		}
+	})
 
-		answered++
+	m, err := quiz.ParseMatcher(match, tolerance)
+	if err != nil {
+		return nil, err
+	}
+
+	// the seed is printed whenever it is used, so the very same session
+	// can be run again
+	random := (shuffle || generate != "") && resumed == nil
+	if random && seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+
+	bank, err := loadBank()
+	if err != nil {
+		return nil, fmt.Errorf("error loading problems: %w", err)
+	}
+
+	if resumed == nil && (categories != "" || difficulties != "") {
+		bank = bank.Filter(func(p quiz.Problem) bool {
+			return oneOf(p.Category, categories) && oneOf(p.Difficulty, difficulties)
+		})
+		if bank.Len() == 0 {
+			return nil, fmt.Errorf("no problems left for -category %q and -difficulty %q", categories, difficulties)
+		}
+	}
+
+	// problems are asked in file order unless shuffling was requested
+	if shuffle && resumed == nil {
+		bank.Shuffle(seed)
+	}
+	if random {
+		fmt.Printf("running quiz with -seed %d\n", seed)
+	}
+
+	q := &quiz.Quiz{
+		Bank:        bank,
+		PerQuestion: perQuestion,
+		Matcher:     m,
+	}
+	if timerOn {
+		q.Limit = time.Duration(timeLimit)
+	}
+	return q, nil
+}
 
-		// check for correctness
-		if input == answer {
-			correct++
+// loadBank loads the problem bank either from the checkpoint of a resumed
+// quiz, from the problems file, the format is determined by the file
+// extension, or from the generator.
+func loadBank() (*quiz.Bank, error) {
+	if resumed != nil {
+		return resumed.Bank()
+	}
+	if generate == "" {
+		// remote problems are loaded from their local copy, which also takes
+		// the place of the problems file from here on, e.g. for -practice
+		if remote.IsURL(problemsPath) {
+			f := &remote.Fetcher{Dir: cacheDir}
+			local, err := f.Fetch(problemsPath)
+			var offline *remote.OfflineError
+			if errors.As(err, &offline) {
+				log.Printf("warning: %s", err)
+			} else if err != nil {
+				return nil, err
+			}
+			problemsPath = local
 		}
+		return quiz.LoadFile(problemsPath)
 	}
-	endQuiz()
+
+	ops, err := quiz.ParseOps(generate)
+	if err != nil {
+		return nil, err
+	}
+	min, max, err := quiz.ParseRange(genRange)
+	if err != nil {
+		return nil, err
+	}
+
+	g := &quiz.Generator{Ops: ops, Min: min, Max: max, Count: genCount, Seed: seed}
+	return g.Load()
 }
 
-func endQuiz() {
+// saveCheckpoint saves the state of the interrupted quiz, unless all
+// questions were asked anyway.
+func saveCheckpoint(q *quiz.Quiz, res *quiz.Result) {
+	cp := q.Checkpoint(res)
+	if len(cp.Records) == q.Bank.Len() {
+		return
+	}
+	if err := cp.Save(checkpoint); err != nil {
+		log.Printf("error saving checkpoint: %s", err)
+		return
+	}
+	fmt.Printf("continue later on with -resume %s\n", checkpoint)
+}
+
+func endQuiz(res *quiz.Result) {
 	// output total number of questions and those which were answered correctly
 	fmt.Println("\n----------------------------------------------")
-	fmt.Printf("%d questions answered from a total of %d questions\n", answered, len(problems))
-	fmt.Printf("%d questions answered correct\n", correct)
-	os.Exit(0)
+	if res.Interrupted {
+		fmt.Println("partial result of the interrupted quiz")
+	}
+	fmt.Printf("%d questions answered from a total of %d questions\n", res.Answered(), res.Total())
+	fmt.Printf("%d questions answered correct\n", res.Correct())
+
+	// the score is only interesting if problems are weighted or categorized
+	cats := res.Categories()
+	if res.MaxScore() != res.Total() || len(cats) > 1 || (len(cats) == 1 && cats[0].Category != "") {
+		fmt.Printf("%d of %d points scored\n", res.Score(), res.MaxScore())
+		for _, c := range cats {
+			name := c.Category
+			if name == "" {
+				name = "(none)"
+			}
+			fmt.Printf("  %-20s %d/%d correct, %d/%d points\n", name, c.Correct, c.Total, c.Score, c.MaxScore)
+		}
+	}
+
+	writeReport(res)
+}
+
+// writeReport exports res to the report file, if one was requested.
+func writeReport(res *quiz.Result) {
+	if reportPath == "" {
+		return
+	}
+	if err := res.WriteReport(reportPath); err != nil {
+		log.Fatalf("error writing report: %s", err)
+	}
+	fmt.Printf("report written to %s\n", reportPath)
+}
+
+// isTerminal reports whether f is a terminal rather than a pipe or file.
+func isTerminal(f *os.File) bool {
+	fi, err := f.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
+
+// defaultCacheDir returns the directory remote problem files are cached
+// in by default, the user's cache directory if there is one.
+func defaultCacheDir() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ".quiz-cache"
+	}
+	return filepath.Join(dir, "quiz")
+}
+
+// oneOf reports whether s is one of the comma separated values in list,
+// ignoring case. An empty list allows everything.
+func oneOf(s, list string) bool {
+	if list == "" {
+		return true
+	}
+	for _, v := range strings.Split(list, ",") {
+		if strings.EqualFold(strings.TrimSpace(v), strings.TrimSpace(s)) {
+			return true
+		}
+	}
+	return false
+}
+
+// minutes is a time.Duration flag which also accepts plain numbers as
+// minutes, so the former -limit usage like -limit 2 keeps working.
+type minutes time.Duration
+
+func (m *minutes) String() string {
+	return time.Duration(*m).String()
+}
+
+func (m *minutes) Set(s string) error {
+	if n, err := strconv.ParseUint(s, 10, 64); err == nil {
+		*m = minutes(time.Duration(n) * time.Minute)
+		return nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*m = minutes(d)
+	return nil
 }
@@ -0,0 +1,241 @@
+package multiplayer
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mbraunwarth/quiz/quiz"
+)
+
+// nameTimeout is the time a new player has to enter their name.
+const nameTimeout = time.Minute
+
+// writeTimeout is the time a write to a player may take, so a stalled
+// connection can't hold up the others.
+const writeTimeout = 5 * time.Second
+
+// Host runs a quiz for several players connecting over TCP, e.g. with
+// netcat or telnet. Every player plays the same problems in their own
+// session with their own timers, the leaderboard is broadcast to all
+// connected players whenever an answer comes in.
+type Host struct {
+	quiz  *quiz.Quiz
+	board *Leaderboard
+
+	mu      sync.Mutex
+	players map[*player]bool
+}
+
+// NewHost returns a host for the given quiz, recording the scores in
+// board.
+func NewHost(q *quiz.Quiz, board *Leaderboard) *Host {
+	return &Host{quiz: q, board: board, players: make(map[*player]bool)}
+}
+
+// Serve accepts players on l until it fails.
+func (h *Host) Serve(l net.Listener) error {
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			return err
+		}
+		go h.handle(conn)
+	}
+}
+
+// player is a single connected player.
+type player struct {
+	conn net.Conn
+	r    *bufio.Reader
+
+	// mu guards writing to conn and prompt, which is repeated after each
+	// broadcast so the player knows what they are answering
+	mu     sync.Mutex
+	prompt string
+}
+
+// printf writes to the player, errors show up when reading anyway.
+func (p *player) printf(format string, a ...interface{}) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.write(fmt.Sprintf(format, a...))
+}
+
+// ask writes the prompt and remembers it for broadcasts.
+func (p *player) ask(prompt string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.prompt = prompt
+	p.write(prompt)
+}
+
+// write writes s within writeTimeout, p.mu must be held. A player whose
+// connection fails is disconnected, ending their game once the next read
+// fails.
+func (p *player) write(s string) {
+	p.conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+	if _, err := io.WriteString(p.conn, s); err != nil {
+		p.conn.Close()
+	}
+}
+
+// readLine reads a single line until the given deadline, a zero
+// deadline means no limit.
+func (p *player) readLine(deadline time.Time) (string, error) {
+	if err := p.conn.SetReadDeadline(deadline); err != nil {
+		return "", err
+	}
+	line, err := p.r.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+func (h *Host) handle(conn net.Conn) {
+	defer conn.Close()
+	p := &player{conn: conn, r: bufio.NewReader(conn)}
+
+	p.printf("welcome to the quiz with %d problems!\n", h.quiz.Bank.Len())
+	p.ask("your name: ")
+	name, err := p.readLine(time.Now().Add(nameTimeout))
+	if err != nil || strings.TrimSpace(name) == "" {
+		return
+	}
+	name = strings.TrimSpace(name)
+
+	sess := h.quiz.Start()
+	e := Entry{
+		Player:   name,
+		Started:  sess.Result().Started,
+		Total:    h.quiz.Bank.Len(),
+		MaxScore: h.quiz.Bank.Points(),
+	}
+	id, err := h.board.Add(e)
+	if err != nil {
+		log.Printf("error saving leaderboard: %s", err)
+	}
+	log.Printf("%s joined from %s", name, conn.RemoteAddr())
+
+	// a player leaving early keeps their entry, marked as left, which runs
+	// after leaving so the broadcast doesn't go to them
+	finished := false
+	defer func() {
+		if !finished {
+			sess.Stop()
+			e.Left = true
+			h.record(id, e, sess)
+			h.broadcast()
+		}
+	}()
+
+	h.join(p)
+	defer h.leave(p)
+	h.broadcast()
+
+	for {
+		prob, i, ok := sess.Next()
+		if !ok {
+			break
+		}
+		p.ask(fmt.Sprintf("[%d/%d] %s", i+1, h.quiz.Bank.Len(), prob.Prompt()))
+
+		line, err := p.readLine(earliest(sess.Deadline(), sess.QuestionDeadline()))
+		if errors.Is(err, os.ErrDeadlineExceeded) {
+			// the session records the timeout on the next access
+			p.printf("\ntime's up!\n")
+			h.record(id, e, sess)
+			continue
+		} else if err != nil {
+			log.Printf("%s left: %s", name, err)
+			return
+		}
+
+		// asking for the hint is no answer, so the question is asked again
+		if strings.TrimSpace(line) == quiz.HintRequest && prob.Hint != "" {
+			p.printf("hint: %s\n", prob.Hint)
+			continue
+		}
+
+		if _, err := sess.Answer(i, line); err != nil {
+			p.printf("too late!\n")
+			continue
+		}
+		if prob.Explanation != "" {
+			p.printf("%s\n", prob.Explanation)
+		}
+		h.record(id, e, sess)
+		h.broadcast()
+	}
+
+	finished = true
+	res := sess.Result()
+	h.record(id, e, sess)
+	p.ask("")
+	p.printf("\nfinished: %d of %d questions answered correct\n", res.Correct(), res.Total())
+	h.broadcast()
+}
+
+// record updates the leaderboard entry id with the state of sess.
+func (h *Host) record(id int, e Entry, sess *quiz.Session) {
+	res := sess.Result()
+	e.Answered = res.Answered()
+	e.Correct = res.Correct()
+	e.Score = res.Score()
+	e.Finished = sess.Done()
+	var took time.Duration
+	for _, rec := range res.Records {
+		took += rec.Duration
+	}
+	e.TimeMS = took.Milliseconds()
+
+	if err := h.board.Update(id, e); err != nil {
+		log.Printf("error saving leaderboard: %s", err)
+	}
+}
+
+func (h *Host) join(p *player) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.players[p] = true
+}
+
+func (h *Host) leave(p *player) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.players, p)
+}
+
+// broadcast sends the current leaderboard to all connected players.
+func (h *Host) broadcast() {
+	board := h.board.String()
+
+	h.mu.Lock()
+	players := make([]*player, 0, len(h.players))
+	for p := range h.players {
+		players = append(players, p)
+	}
+	h.mu.Unlock()
+
+	for _, p := range players {
+		p.mu.Lock()
+		p.write("\n" + board + p.prompt)
+		p.mu.Unlock()
+	}
+}
+
+// earliest returns the earlier of two deadlines, where zero means none.
+func earliest(a, b time.Time) time.Time {
+	if a.IsZero() || (!b.IsZero() && b.Before(a)) {
+		return b
+	}
+	return a
+}
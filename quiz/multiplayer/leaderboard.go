@@ -0,0 +1,138 @@
+package multiplayer
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Entry is the score of a single player in a single game.
+type Entry struct {
+	Player   string    `json:"player"`
+	Started  time.Time `json:"started"`
+	Total    int       `json:"total"`
+	Answered int       `json:"answered"`
+	Correct  int       `json:"correct"`
+	Score    int       `json:"score"`
+	MaxScore int       `json:"max_score"`
+	TimeMS   int64     `json:"time_ms"`
+	Finished bool      `json:"finished"`
+
+	// Left is set if the player left before finishing.
+	Left bool `json:"left,omitempty"`
+}
+
+// Leaderboard ranks the games of all players. It is kept in a JSON file
+// which is rewritten on every change, so the leaderboard survives a
+// restart. A Leaderboard is safe for concurrent use.
+type Leaderboard struct {
+	path string
+
+	mu      sync.Mutex
+	entries []Entry
+}
+
+// OpenLeaderboard returns the leaderboard stored at path, which is
+// empty if the file does not exist yet.
+func OpenLeaderboard(path string) (*Leaderboard, error) {
+	l := &Leaderboard{path: path}
+
+	content, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return l, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(content, &l.entries); err != nil {
+		return nil, fmt.Errorf("error decoding leaderboard %s: %w", path, err)
+	}
+	return l, nil
+}
+
+// Add adds a new entry and returns its id for later updates.
+func (l *Leaderboard) Add(e Entry) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.entries = append(l.entries, e)
+	return len(l.entries) - 1, l.save()
+}
+
+// Update replaces the entry with the given id.
+func (l *Leaderboard) Update(id int, e Entry) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if id < 0 || id >= len(l.entries) {
+		return fmt.Errorf("unknown leaderboard entry %d", id)
+	}
+	l.entries[id] = e
+	return l.save()
+}
+
+// Top returns the n best entries, ranked by score first and by the time
+// taken second. n <= 0 returns all entries.
+func (l *Leaderboard) Top(n int) []Entry {
+	l.mu.Lock()
+	top := make([]Entry, len(l.entries))
+	copy(top, l.entries)
+	l.mu.Unlock()
+
+	sort.SliceStable(top, func(i, j int) bool {
+		if top[i].Score != top[j].Score {
+			return top[i].Score > top[j].Score
+		}
+		return top[i].TimeMS < top[j].TimeMS
+	})
+	if n > 0 && n < len(top) {
+		top = top[:n]
+	}
+	return top
+}
+
+// String formats the ten best entries as a table.
+func (l *Leaderboard) String() string {
+	var b strings.Builder
+	b.WriteString("----------------- leaderboard -----------------\n")
+	for i, e := range l.Top(10) {
+		status := ""
+		switch {
+		case e.Left:
+			status = " (left)"
+		case !e.Finished:
+			status = " (playing)"
+		}
+		fmt.Fprintf(&b, "%2d. %-20s %3d/%-3d points %7.1fs%s\n", i+1, e.Player, e.Score, e.MaxScore, float64(e.TimeMS)/1000, status)
+	}
+	return b.String()
+}
+
+// save writes all entries to the leaderboard file, l.mu must be held. The
+// file is replaced atomically so a crash never leaves it half written.
+func (l *Leaderboard) save() error {
+	content, err := json.MarshalIndent(l.entries, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(l.path), filepath.Base(l.path)+".*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(content); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), l.path)
+}
@@ -0,0 +1,201 @@
+// Package practice schedules problems by spaced repetition. It keeps a
+// history of how well each user knew each problem, and brings the weak
+// problems up more often using a variant of the SM-2 algorithm.
+package practice
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mbraunwarth/quiz/quiz"
+)
+
+const (
+	// initialEase is the ease factor of a problem never seen before.
+	initialEase = 2.5
+
+	// minEase is the lowest possible ease factor.
+	minEase = 1.3
+
+	day = 24 * time.Hour
+)
+
+// Item is the learning state of a single problem for a single user.
+type Item struct {
+	// Ease grows with each quick correct answer and shrinks with each
+	// mistake, it scales the interval until the problem is due again.
+	Ease float64 `json:"ease"`
+
+	// Interval is the number of days until the problem is due again.
+	Interval int `json:"interval_days"`
+
+	// Repetitions counts the correct answers in a row.
+	Repetitions int `json:"repetitions"`
+
+	Due    time.Time `json:"due"`
+	Seen   int       `json:"seen"`
+	Missed int       `json:"missed"`
+
+	// AvgMS is the average time taken to answer in milliseconds.
+	AvgMS int64 `json:"avg_ms"`
+}
+
+// History holds the items of all users, keyed by user and question. It is
+// stored as a JSON file.
+type History struct {
+	path  string
+	Users map[string]map[string]*Item `json:"users"`
+}
+
+// PathFor returns the path of the history file belonging to a problems
+// file, which lives right next to it, e.g. problems.history.json for
+// problems.csv.
+func PathFor(problemsPath string) string {
+	ext := filepath.Ext(problemsPath)
+	return strings.TrimSuffix(problemsPath, ext) + ".history.json"
+}
+
+// Open reads the history stored at path, which is empty if the file does
+// not exist yet.
+func Open(path string) (*History, error) {
+	h := &History{path: path, Users: make(map[string]map[string]*Item)}
+
+	content, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return h, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(content, h); err != nil {
+		return nil, err
+	}
+	if h.Users == nil {
+		h.Users = make(map[string]map[string]*Item)
+	}
+	return h, nil
+}
+
+// Save writes the history back to its file.
+func (h *History) Save() error {
+	content, err := json.MarshalIndent(h, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(h.path, content, 0644)
+}
+
+// Schedule returns a new bank with the problems of b ordered for practice
+// by user at the given time. Problems which are due come first, the
+// weakest of them leading, followed by problems never seen before in their
+// original order. Problems not due yet come last, the soonest due first.
+func (h *History) Schedule(user string, b *quiz.Bank, now time.Time) *quiz.Bank {
+	items := h.Users[user]
+
+	var due, fresh, later []quiz.Problem
+	for _, p := range b.Problems {
+		it, ok := items[p.Question]
+		switch {
+		case !ok:
+			fresh = append(fresh, p)
+		case !it.Due.After(now):
+			due = append(due, p)
+		default:
+			later = append(later, p)
+		}
+	}
+
+	sort.SliceStable(due, func(i, j int) bool {
+		a, b := items[due[i].Question], items[due[j].Question]
+		if a.Ease != b.Ease {
+			return a.Ease < b.Ease
+		}
+		return a.Due.Before(b.Due)
+	})
+	sort.SliceStable(later, func(i, j int) bool {
+		return items[later[i].Question].Due.Before(items[later[j].Question].Due)
+	})
+
+	scheduled := quiz.NewBank()
+	for _, ps := range [][]quiz.Problem{due, fresh, later} {
+		for _, p := range ps {
+			// the problems come from a bank, so they can't be duplicates
+			scheduled.Add(p)
+		}
+	}
+	return scheduled
+}
+
+// Record updates the items of user with the outcome of a quiz finished at
+// the given time. Questions which were never asked are left untouched.
+func (h *History) Record(user string, res *quiz.Result, now time.Time) {
+	items, ok := h.Users[user]
+	if !ok {
+		items = make(map[string]*Item)
+		h.Users[user] = items
+	}
+
+	for _, rec := range res.Records {
+		if !rec.Asked() {
+			continue
+		}
+		it, ok := items[rec.Question]
+		if !ok {
+			it = &Item{Ease: initialEase}
+			items[rec.Question] = it
+		}
+		it.update(quality(rec), now)
+
+		it.AvgMS = (it.AvgMS*int64(it.Seen) + rec.Duration.Milliseconds()) / int64(it.Seen+1)
+		it.Seen++
+		if !rec.Correct {
+			it.Missed++
+		}
+	}
+}
+
+// quality grades an answer from 0 (no answer) to 5 (quick and correct),
+// as the SM-2 algorithm expects.
+func quality(rec quiz.Record) int {
+	switch {
+	case !rec.Answered:
+		return 0
+	case !rec.Correct:
+		return 1
+	case rec.Duration <= 5*time.Second:
+		return 5
+	case rec.Duration <= 15*time.Second:
+		return 4
+	}
+	return 3
+}
+
+// update applies an answer of quality q given at now following SM-2.
+func (it *Item) update(q int, now time.Time) {
+	if q < 3 {
+		// start learning the problem from scratch, other than in SM-2
+		// proper it is due again right away instead of the next day, so
+		// missed problems come first in the very next run
+		it.Repetitions = 0
+		it.Interval = 0
+	} else {
+		it.Repetitions++
+		switch it.Repetitions {
+		case 1:
+			it.Interval = 1
+		case 2:
+			it.Interval = 6
+		default:
+			it.Interval = int(math.Round(float64(it.Interval) * it.Ease))
+		}
+	}
+
+	f := float64(5 - q)
+	it.Ease = math.Max(minEase, it.Ease+0.1-f*(0.08+f*0.02))
+	it.Due = now.Add(time.Duration(it.Interval) * day)
+}
@@ -0,0 +1,60 @@
+package quiz
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"time"
+)
+
+// Checkpoint is the state of an interrupted quiz, from which it can be
+// resumed later on. It holds the problems in the order they are asked, so
+// a shuffled or generated quiz resumes with the very same problems.
+type Checkpoint struct {
+	Problems []Problem     `json:"problems"`
+	Records  []Record      `json:"records"`
+	Started  time.Time     `json:"started"`
+	Elapsed  time.Duration `json:"elapsed"`
+}
+
+// Checkpoint returns the checkpoint to resume the quiz from after it was
+// interrupted with result res.
+func (q *Quiz) Checkpoint(res *Result) *Checkpoint {
+	// only the questions actually asked are kept, the rest is still to go
+	n := 0
+	for n < len(res.Records) && res.Records[n].Asked() {
+		n++
+	}
+	return &Checkpoint{
+		Problems: q.Bank.Problems,
+		Records:  res.Records[:n],
+		Started:  res.Started,
+		Elapsed:  res.Elapsed,
+	}
+}
+
+// Bank returns the problems of the checkpoint as bank.
+func (cp *Checkpoint) Bank() (*Bank, error) {
+	return bankOf(cp.Problems)
+}
+
+// Save writes the checkpoint as JSON to the file at path.
+func (cp *Checkpoint) Save(path string) error {
+	content, err := json.MarshalIndent(cp, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, content, 0644)
+}
+
+// LoadCheckpoint reads the checkpoint saved at path.
+func LoadCheckpoint(path string) (*Checkpoint, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	cp := &Checkpoint{}
+	if err := json.Unmarshal(content, cp); err != nil {
+		return nil, err
+	}
+	return cp, nil
+}
@@ -0,0 +1,48 @@
+package quiz
+
+import "time"
+
+// Clock tells the time and creates timers. The quiz uses it for all of its
+// timing, so tests can replace the wall clock with a fake one.
+type Clock interface {
+	Now() time.Time
+	NewTimer(d time.Duration) Timer
+}
+
+// Timer is a single timer created by a Clock, see time.Timer.
+type Timer interface {
+	C() <-chan time.Time
+	Stop() bool
+}
+
+// wallClock is the Clock backed by the time package.
+type wallClock struct{}
+
+func (wallClock) Now() time.Time {
+	return time.Now()
+}
+
+func (wallClock) NewTimer(d time.Duration) Timer {
+	return wallTimer{time.NewTimer(d)}
+}
+
+// wallTimer wraps a time.Timer as Timer.
+type wallTimer struct {
+	t *time.Timer
+}
+
+func (t wallTimer) C() <-chan time.Time {
+	return t.t.C
+}
+
+func (t wallTimer) Stop() bool {
+	return t.t.Stop()
+}
+
+// clock returns the clock of the quiz, the wall clock if none is set.
+func (q *Quiz) clock() Clock {
+	if q.Clock == nil {
+		return wallClock{}
+	}
+	return q.Clock
+}
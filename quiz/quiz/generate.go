@@ -0,0 +1,136 @@
+package quiz
+
+import (
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+)
+
+// maxAttempts limits how often the generator tries to find a new problem
+// for each requested one before giving up, as small ranges only allow a
+// few distinct problems.
+const maxAttempts = 100
+
+// MaxOperand limits the magnitude of the operands of generated problems,
+// so neither the size of the range nor the product of two operands
+// overflows an int, even on 32 bit platforms.
+const MaxOperand = 10000
+
+// Generator creates arithmetic problems with their correct answers on
+// the fly. It implements the Loader interface, the same seed always
+// results in the same problems.
+type Generator struct {
+	// Ops are the operations to generate problems for, any of add, sub,
+	// mul and div.
+	Ops []string
+
+	// Min and Max limit the operands, both inclusive and within
+	// -MaxOperand..MaxOperand.
+	Min, Max int
+
+	// Count is the number of problems to generate, at least one.
+	Count int
+
+	Seed int64
+}
+
+// operations maps the name of an operation to a function generating a
+// question and its answer for two operands.
+var operations = map[string]func(a, b int) (string, int, bool){
+	"add": func(a, b int) (string, int, bool) {
+		return fmt.Sprintf("%d+%d", a, b), a + b, true
+	},
+	"sub": func(a, b int) (string, int, bool) {
+		// keep the results non-negative
+		if a < b {
+			a, b = b, a
+		}
+		return fmt.Sprintf("%d-%d", a, b), a - b, true
+	},
+	"mul": func(a, b int) (string, int, bool) {
+		return fmt.Sprintf("%d*%d", a, b), a * b, true
+	},
+	"div": func(a, b int) (string, int, bool) {
+		// build the dividend from both operands so the result is whole
+		if b == 0 {
+			return "", 0, false
+		}
+		return fmt.Sprintf("%d/%d", a*b, b), a, true
+	},
+}
+
+// ParseOps parses a comma separated list of operations like add,mul.
+func ParseOps(s string) ([]string, error) {
+	var ops []string
+	for _, op := range strings.Split(s, ",") {
+		op = strings.TrimSpace(op)
+		if _, ok := operations[op]; !ok {
+			return nil, fmt.Errorf("unknown operation %q, use add, sub, mul or div", op)
+		}
+		ops = append(ops, op)
+	}
+	return ops, nil
+}
+
+// ParseRange parses an inclusive range of operands like 1..20, see
+// MaxOperand.
+func ParseRange(s string) (min, max int, err error) {
+	parts := strings.Split(s, "..")
+	if len(parts) != 2 {
+		return 0, 0, fmt.Errorf("invalid range %q, use min..max", s)
+	}
+	if min, err = strconv.Atoi(strings.TrimSpace(parts[0])); err != nil {
+		return 0, 0, fmt.Errorf("invalid range %q: %w", s, err)
+	}
+	if max, err = strconv.Atoi(strings.TrimSpace(parts[1])); err != nil {
+		return 0, 0, fmt.Errorf("invalid range %q: %w", s, err)
+	}
+	if err := checkRange(min, max); err != nil {
+		return 0, 0, fmt.Errorf("invalid range %q: %w", s, err)
+	}
+	return min, max, nil
+}
+
+// checkRange makes sure min..max is a valid range of operands.
+func checkRange(min, max int) error {
+	if min > max {
+		return fmt.Errorf("min is greater than max")
+	}
+	if min < -MaxOperand || max > MaxOperand {
+		return fmt.Errorf("operands must be within %d..%d", -MaxOperand, MaxOperand)
+	}
+	return nil
+}
+
+// Load implements the Loader interface.
+func (g *Generator) Load() (*Bank, error) {
+	if len(g.Ops) == 0 {
+		return nil, fmt.Errorf("no operations to generate problems for")
+	}
+	if err := checkRange(g.Min, g.Max); err != nil {
+		return nil, fmt.Errorf("invalid range %d..%d: %w", g.Min, g.Max, err)
+	}
+	if g.Count <= 0 {
+		return nil, fmt.Errorf("invalid count %d, generate at least one problem", g.Count)
+	}
+
+	r := rand.New(rand.NewSource(g.Seed))
+	b := NewBank()
+	for attempts := 0; b.Len() < g.Count; attempts++ {
+		if attempts == g.Count*maxAttempts {
+			return nil, fmt.Errorf("range %d..%d is too small for %d distinct problems", g.Min, g.Max, g.Count)
+		}
+
+		op := operations[g.Ops[r.Intn(len(g.Ops))]]
+		a, c := g.Min+r.Intn(g.Max-g.Min+1), g.Min+r.Intn(g.Max-g.Min+1)
+		q, answer, ok := op(a, c)
+		if !ok {
+			continue
+		}
+
+		// duplicates are simply generated anew
+		b.Add(Problem{Question: q, Answer: strconv.Itoa(answer)})
+	}
+	return b, nil
+}
@@ -0,0 +1,51 @@
+package quiz_test
+
+import (
+	"testing"
+
+	"github.com/mbraunwarth/quiz/quiz"
+)
+
+func TestParseRange(t *testing.T) {
+	tests := []struct {
+		in       string
+		min, max int
+		ok       bool
+	}{
+		{"1..10", 1, 10, true},
+		{"-5..5", -5, 5, true},
+		{"-10000..10000", -10000, 10000, true},
+		{"10..1", 0, 0, false},
+		{"1-10", 0, 0, false},
+		{"-10001..1", 0, 0, false},
+		{"-5000000000000000000..5000000000000000000", 0, 0, false},
+	}
+	for _, tt := range tests {
+		min, max, err := quiz.ParseRange(tt.in)
+		if (err == nil) != tt.ok || min != tt.min || max != tt.max {
+			t.Errorf("ParseRange(%q) = %d, %d, %v", tt.in, min, max, err)
+		}
+	}
+}
+
+func TestGenerator(t *testing.T) {
+	g := &quiz.Generator{Ops: []string{"add", "mul"}, Min: -quiz.MaxOperand, Max: quiz.MaxOperand, Count: 20, Seed: 1}
+	b, err := g.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.Len() != 20 {
+		t.Errorf("got %d problems, want 20", b.Len())
+	}
+
+	for _, g := range []*quiz.Generator{
+		{Ops: []string{"add"}, Min: 1, Max: 10, Count: 0},
+		{Ops: []string{"add"}, Min: 1, Max: 10, Count: -1},
+		{Ops: []string{"add"}, Min: -1 << 62, Max: 1 << 62, Count: 1},
+		{Ops: []string{"add"}, Min: 1, Max: 1, Count: 2},
+	} {
+		if _, err := g.Load(); err == nil {
+			t.Errorf("%+v: got no error", g)
+		}
+	}
+}
@@ -0,0 +1,179 @@
+package quiz
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Diagnostic is a single finding about a problems file. Line is the line
+// of a CSV file the finding belongs to, for JSON and YAML files Problem
+// is the number of the problem instead. Either is zero if the finding is
+// about the file as a whole.
+type Diagnostic struct {
+	Line    int
+	Problem int
+	Message string
+}
+
+func (d Diagnostic) String() string {
+	switch {
+	case d.Line > 0:
+		return fmt.Sprintf("%d: %s", d.Line, d.Message)
+	case d.Problem > 0:
+		return fmt.Sprintf("problem %d: %s", d.Problem, d.Message)
+	}
+	return d.Message
+}
+
+// Lint checks the problems file at path and returns all findings, other
+// than a Loader which stops at the first one. The format is determined by
+// the file extension. The error is only set if the file can't be read.
+func Lint(path string) ([]Diagnostic, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".csv":
+		return lintCSV(f)
+	case ".json", ".yaml", ".yml":
+		return lintDecoded(f, ext)
+	default:
+		return nil, fmt.Errorf("unsupported problem file format %q", ext)
+	}
+}
+
+// lintCSV checks CSV data row by row.
+func lintCSV(r io.Reader) ([]Diagnostic, error) {
+	records, lines, err := readCSV(r, true)
+	if err != nil {
+		// the csv package stops at syntax errors like stray quotes
+		return []Diagnostic{{Message: err.Error()}}, nil
+	}
+	if len(records) == 0 {
+		return []Diagnostic{{Message: "no problems"}}, nil
+	}
+
+	var diags []Diagnostic
+	report := func(line int, format string, a ...interface{}) {
+		diags = append(diags, Diagnostic{Line: line, Message: fmt.Sprintf(format, a...)})
+	}
+
+	// without header the columns are question and answer
+	cols := map[string]int{"question": 0, "answer": 1}
+	want, first := len(records[0]), 0
+	if isHeader(records[0]) {
+		if cols, err = parseHeader(records[0]); err != nil {
+			// without the columns the rows can't be checked
+			report(lines[0], "%s", err)
+			return diags, nil
+		}
+		first = 1
+	} else if want != 2 {
+		report(lines[0], "expected question and answer, got %d field(s), add a header to use more columns", want)
+	}
+
+	l := linter{seen: make(map[string]string)}
+	for i, row := range records[first:] {
+		line := lines[first+i]
+		if len(row) != want {
+			report(line, "expected %d field(s), got %d", want, len(row))
+			continue
+		}
+
+		p, err := problemOf(row, cols)
+		if err != nil {
+			report(line, "%s", err)
+			continue
+		}
+		for _, msg := range l.check(p, fmt.Sprintf("line %d", line)) {
+			report(line, "%s", msg)
+		}
+	}
+	if len(records) == first {
+		report(0, "no problems")
+	}
+	return diags, nil
+}
+
+// lintDecoded checks JSON or YAML data problem by problem.
+func lintDecoded(r io.Reader, ext string) ([]Diagnostic, error) {
+	content, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// unlike the loaders, unknown keys are reported as they are likely
+	// misspelled ones
+	var problems []Problem
+	if ext == ".json" {
+		dec := json.NewDecoder(bytes.NewReader(content))
+		dec.DisallowUnknownFields()
+		err = dec.Decode(&problems)
+	} else {
+		err = yaml.UnmarshalStrict(content, &problems)
+	}
+	if err != nil {
+		return []Diagnostic{{Message: err.Error()}}, nil
+	}
+	if len(problems) == 0 {
+		return []Diagnostic{{Message: "no problems"}}, nil
+	}
+
+	var diags []Diagnostic
+	l := linter{seen: make(map[string]string)}
+	for i, p := range problems {
+		for _, msg := range l.check(p, fmt.Sprintf("problem %d", i+1)) {
+			diags = append(diags, Diagnostic{Problem: i + 1, Message: msg})
+		}
+	}
+	return diags, nil
+}
+
+// linter checks problems one after another.
+type linter struct {
+	// seen maps each question to where it was seen first
+	seen map[string]string
+}
+
+// check returns all findings about p, which is found at the given place
+// like line 3 or problem 2.
+func (l *linter) check(p Problem, at string) []string {
+	var msgs []string
+	if strings.TrimSpace(p.Question) == "" {
+		msgs = append(msgs, "empty question")
+	}
+	if strings.TrimSpace(p.Answer) == "" {
+		msgs = append(msgs, "empty answer")
+	}
+
+	if first, ok := l.seen[p.Question]; ok {
+		msgs = append(msgs, fmt.Sprintf("%s %q, first at %s", ErrDuplicate, p.Question, first))
+	} else {
+		l.seen[p.Question] = at
+	}
+
+	if p.Type == "" {
+		p.Type = TypeText
+	}
+	if err := p.validate(); err != nil {
+		msgs = append(msgs, err.Error())
+	}
+	if err := p.validateType(); err != nil {
+		msgs = append(msgs, err.Error())
+	}
+	if p.Points < 0 {
+		msgs = append(msgs, fmt.Sprintf("negative points for %q", p.Question))
+	}
+	return msgs
+}
@@ -0,0 +1,98 @@
+package quiz_test
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/mbraunwarth/quiz/quiz"
+)
+
+func TestLint(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		want    []string
+	}{
+		{
+			name:    "clean",
+			file:    "problems.csv",
+			content: "5+5,10\n7+3,10\n",
+		},
+		{
+			name:    "header error",
+			file:    "problems.csv",
+			content: "question,answer,categroy\n5+5,10,add\n,,\n",
+			want:    []string{`1: unknown column "categroy"`},
+		},
+		{
+			name:    "multi-line quoted field",
+			file:    "problems.csv",
+			content: "question,answer,explanation\n5+5,10,\"one\nand \"\"two\"\"\nlines\"\n,10,none\n",
+			want:    []string{"5: empty question"},
+		},
+		{
+			name:    "blank lines",
+			file:    "problems.csv",
+			content: "5+5,10\n\n\r\n7+3,\n\n5+5,10\n",
+			want:    []string{"4: empty answer", `6: duplicate question "5+5", first at line 1`},
+		},
+		{
+			name:    "wrong field counts",
+			file:    "problems.csv",
+			content: "question,answer,hint\n5+5,10\n7+3,10,seven,three\n9-4,5,\n",
+			want:    []string{"2: expected 3 field(s), got 2", "3: expected 3 field(s), got 4"},
+		},
+		{
+			name:    "too many fields without header",
+			file:    "problems.csv",
+			content: "5+5,10,add\n",
+			want:    []string{"1: expected question and answer, got 3 field(s), add a header to use more columns"},
+		},
+		{
+			name:    "empty",
+			file:    "problems.csv",
+			content: "\n",
+			want:    []string{"no problems"},
+		},
+		{
+			name:    "numeric answers",
+			file:    "problems.csv",
+			content: "question,answer,type\n5+5,10|10.0,numeric\n7+3,ten,numeric\n9-4,/^5$/,numeric\n",
+			want:    []string{`3: answer "ten" of numeric question "7+3" is no number`},
+		},
+		{
+			name:    "unknown key",
+			file:    "problems.json",
+			content: `[{"question": "5+5", "answer": "10", "hnt": "ten"}]`,
+			want:    []string{`json: unknown field "hnt"`},
+		},
+		{
+			name:    "problems",
+			file:    "problems.yaml",
+			content: "- question: 5+5\n  answer: 10\n- question: 5+5\n  answer: 10\n  points: -1\n",
+			want:    []string{`problem 2: duplicate question "5+5", first at problem 1`, `problem 2: negative points for "5+5"`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.file)
+			if err := ioutil.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+			diags, err := quiz.Lint(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, d := range diags {
+				got = append(got, d.String())
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,277 @@
+package quiz
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Loader loads problems from some source into a Bank.
+type Loader interface {
+	Load() (*Bank, error)
+}
+
+// CSVLoader reads problems from CSV data, one problem per row in the
+// form question,answer.
+//
+// If the first row is a header naming the columns, the columns can be in
+// any order and the extended schema is available:
+//
+//	question     the question, required
+//	answer       the answer, required
+//	type         text, choice, bool or numeric, see Type
+//	options      the options of a multiple choice question separated by |
+//	hint         a hint the user can ask for
+//	explanation  an explanation shown after answering
+//	category     the category of the problem
+//	difficulty   the difficulty of the problem, like easy or hard
+//	points       what a correct answer is worth, one if empty
+type CSVLoader struct {
+	r io.Reader
+}
+
+// NewCSVLoader returns a loader reading CSV data from r.
+func NewCSVLoader(r io.Reader) *CSVLoader {
+	return &CSVLoader{r: r}
+}
+
+// Load implements the Loader interface.
+func (l *CSVLoader) Load() (*Bank, error) {
+	// parse csv content to records variable resulting in a 2D slice
+	records, lines, err := readCSV(l.r, false)
+	if err != nil {
+		return nil, err
+	}
+
+	// without header the columns are question and answer
+	cols := map[string]int{"question": 0, "answer": 1}
+	first := 0
+	if len(records) > 0 && isHeader(records[0]) {
+		if cols, err = parseHeader(records[0]); err != nil {
+			return nil, fmt.Errorf("line 1: %w", err)
+		}
+		first = 1
+	}
+
+	b := NewBank()
+	for i, row := range records[first:] {
+		line := lines[first+i]
+		if len(row) < 2 {
+			return nil, fmt.Errorf("line %d: expected question and answer, got %d field(s)", line, len(row))
+		}
+
+		p, err := problemOf(row, cols)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		if err := b.Add(p); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+	}
+	return b, nil
+}
+
+// readCSV reads all records from r along with the line each of them
+// starts on, which differs from the record number for blank lines and
+// quoted fields spanning several lines. Unless lenient, all records must
+// have the same number of fields.
+func readCSV(r io.Reader, lenient bool) (records [][]string, lines []int, err error) {
+	content, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cr := csv.NewReader(bytes.NewReader(content))
+	if lenient {
+		cr.FieldsPerRecord = -1
+	}
+	if records, err = cr.ReadAll(); err != nil {
+		return nil, nil, fmt.Errorf("error reading csv: %w", err)
+	}
+
+	// a record starts on every line which is neither blank nor within a
+	// quoted field, where each quote toggles between both, so an escaped
+	// quote "" doesn't change anything
+	quoted := false
+	for i, line := range strings.Split(string(content), "\n") {
+		if !quoted && strings.TrimRight(line, "\r") != "" {
+			lines = append(lines, i+1)
+		}
+		if strings.Count(line, `"`)%2 == 1 {
+			quoted = !quoted
+		}
+	}
+	return records, lines, nil
+}
+
+// columns are the known columns of a CSV header.
+var columns = []string{
+	"question", "answer", "type", "options", "hint", "explanation",
+	"category", "difficulty", "points",
+}
+
+// isHeader reports whether row is a header, which is the case if it names
+// both the question and the answer column.
+func isHeader(row []string) bool {
+	var question, answer bool
+	for _, name := range row {
+		name = strings.ToLower(strings.TrimSpace(name))
+		question = question || name == "question"
+		answer = answer || name == "answer"
+	}
+	return question && answer
+}
+
+// parseHeader maps the names of the columns in a header to their index.
+func parseHeader(row []string) (map[string]int, error) {
+	cols := make(map[string]int, len(row))
+	for i, name := range row {
+		name = strings.ToLower(strings.TrimSpace(name))
+		known := false
+		for _, c := range columns {
+			known = known || c == name
+		}
+		if !known {
+			return nil, fmt.Errorf("unknown column %q", name)
+		}
+		if _, ok := cols[name]; ok {
+			return nil, fmt.Errorf("duplicate column %q", name)
+		}
+		cols[name] = i
+	}
+	return cols, nil
+}
+
+// problemOf builds a problem from a row with the given columns.
+func problemOf(row []string, cols map[string]int) (Problem, error) {
+	field := func(name string) string {
+		if i, ok := cols[name]; ok && i < len(row) {
+			return row[i]
+		}
+		return ""
+	}
+
+	t, err := ParseType(field("type"))
+	if err != nil {
+		return Problem{}, err
+	}
+	p := Problem{
+		Question:    field("question"),
+		Answer:      field("answer"),
+		Type:        t,
+		Hint:        field("hint"),
+		Explanation: field("explanation"),
+		Category:    field("category"),
+		Difficulty:  field("difficulty"),
+	}
+	if pts := strings.TrimSpace(field("points")); pts != "" {
+		if p.Points, err = strconv.Atoi(pts); err != nil {
+			return Problem{}, fmt.Errorf("invalid points %q", pts)
+		}
+	}
+	if o := field("options"); o != "" {
+		p.Options = strings.Split(o, "|")
+	}
+	return p, nil
+}
+
+// JSONLoader reads problems from a JSON array of objects with the
+// keys question and answer.
+type JSONLoader struct {
+	r io.Reader
+}
+
+// NewJSONLoader returns a loader reading JSON data from r.
+func NewJSONLoader(r io.Reader) *JSONLoader {
+	return &JSONLoader{r: r}
+}
+
+// Load implements the Loader interface.
+func (l *JSONLoader) Load() (*Bank, error) {
+	var problems []Problem
+	if err := json.NewDecoder(l.r).Decode(&problems); err != nil {
+		return nil, fmt.Errorf("error decoding json: %w", err)
+	}
+	return bankOf(problems)
+}
+
+// YAMLLoader reads problems from a YAML sequence of mappings with the
+// keys question and answer.
+type YAMLLoader struct {
+	r io.Reader
+}
+
+// NewYAMLLoader returns a loader reading YAML data from r.
+func NewYAMLLoader(r io.Reader) *YAMLLoader {
+	return &YAMLLoader{r: r}
+}
+
+// Load implements the Loader interface.
+func (l *YAMLLoader) Load() (*Bank, error) {
+	content, err := ioutil.ReadAll(l.r)
+	if err != nil {
+		return nil, err
+	}
+
+	var problems []Problem
+	if err := yaml.Unmarshal(content, &problems); err != nil {
+		return nil, fmt.Errorf("error decoding yaml: %w", err)
+	}
+	return bankOf(problems)
+}
+
+// NewLoader returns the loader matching the given file extension
+// (.csv, .json, .yaml or .yml) reading from r.
+func NewLoader(ext string, r io.Reader) (Loader, error) {
+	switch strings.ToLower(ext) {
+	case ".csv":
+		return NewCSVLoader(r), nil
+	case ".json":
+		return NewJSONLoader(r), nil
+	case ".yaml", ".yml":
+		return NewYAMLLoader(r), nil
+	}
+	return nil, fmt.Errorf("unsupported problem file format %q", ext)
+}
+
+// LoadFile loads the problems stored in the file at path. The format is
+// determined by the file extension.
+func LoadFile(path string) (*Bank, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	l, err := NewLoader(filepath.Ext(path), f)
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := l.Load()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return b, nil
+}
+
+// bankOf fills a new bank with the given problems, reporting duplicates
+// by their position in the list.
+func bankOf(problems []Problem) (*Bank, error) {
+	b := NewBank()
+	for i, p := range problems {
+		if err := b.Add(p); err != nil {
+			return nil, fmt.Errorf("problem %d: %w", i+1, err)
+		}
+	}
+	return b, nil
+}
@@ -0,0 +1,141 @@
+package quiz
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Matcher decides whether an input matches an expected answer.
+type Matcher interface {
+	Match(input, answer string) bool
+}
+
+// MatcherFunc adapts an ordinary function to the Matcher interface.
+type MatcherFunc func(input, answer string) bool
+
+// Match implements the Matcher interface.
+func (f MatcherFunc) Match(input, answer string) bool {
+	return f(input, answer)
+}
+
+var (
+	// Exact only accepts an input equal to the answer byte by byte.
+	Exact Matcher = MatcherFunc(func(input, answer string) bool {
+		return input == answer
+	})
+
+	// Fold ignores surrounding white space and letter case.
+	Fold Matcher = MatcherFunc(func(input, answer string) bool {
+		return strings.EqualFold(strings.TrimSpace(input), strings.TrimSpace(answer))
+	})
+)
+
+// Numeric returns a matcher treating input and answer as numbers, which
+// are equal if they differ by at most tolerance, so 10 and 10.0 match.
+// If either of both is not a number it falls back to Fold.
+func Numeric(tolerance float64) Matcher {
+	return numeric(tolerance)
+}
+
+// numeric is the matcher returned by Numeric.
+type numeric float64
+
+// Match implements the Matcher interface.
+func (n numeric) Match(input, answer string) bool {
+	x, errX := strconv.ParseFloat(strings.TrimSpace(input), 64)
+	y, errY := strconv.ParseFloat(strings.TrimSpace(answer), 64)
+	if errX != nil || errY != nil {
+		return Fold.Match(input, answer)
+	}
+	return math.Abs(x-y) <= float64(n)
+}
+
+// ParseMatcher returns the matcher with the given name, one of exact,
+// fold or numeric. The tolerance is only used by the numeric matcher.
+func ParseMatcher(name string, tolerance float64) (Matcher, error) {
+	switch name {
+	case "exact":
+		return Exact, nil
+	case "fold":
+		return Fold, nil
+	case "numeric":
+		return Numeric(tolerance), nil
+	}
+	return nil, fmt.Errorf("unknown matcher %q, use exact, fold or numeric", name)
+}
+
+// Accepted returns all answers accepted for the problem. Several answers
+// are separated by a pipe in the answer, like 10|ten. An answer enclosed
+// in slashes, like /^1?0$/, is a regular expression instead and taken as
+// a whole.
+func (p Problem) Accepted() []string {
+	if isRegexp(p.Answer) {
+		return []string{p.Answer}
+	}
+	return strings.Split(p.Answer, "|")
+}
+
+// Check reports whether input is one of the accepted answers according to
+// m. Regular expression answers are matched against the trimmed input
+// regardless of m. Questions of a type other than text bring their own
+// way of comparing: options and booleans are compared ignoring case, and
+// numeric questions numerically even if m is not the numeric matcher.
+func (p Problem) Check(input string, m Matcher) bool {
+	switch p.Type {
+	case TypeChoice:
+		input = p.choice(input)
+	case TypeBool:
+		given, ok := parseBool(input)
+		for _, a := range p.Accepted() {
+			if want, _ := parseBool(a); ok && given == want {
+				return true
+			}
+		}
+		return false
+	case TypeNumeric:
+		if _, ok := m.(numeric); !ok {
+			m = Numeric(DefaultTolerance)
+		}
+	}
+
+	for _, a := range p.Accepted() {
+		if p.Type == TypeChoice && !isRegexp(a) {
+			if strings.EqualFold(input, p.choice(a)) {
+				return true
+			}
+			continue
+		}
+		if isRegexp(a) {
+			re, err := regexp.Compile(a[1 : len(a)-1])
+			if err == nil && re.MatchString(strings.TrimSpace(input)) {
+				return true
+			}
+			continue
+		}
+		if m.Match(input, a) {
+			return true
+		}
+	}
+	return false
+}
+
+// validate makes sure all regular expression answers compile.
+func (p Problem) validate() error {
+	for _, a := range p.Accepted() {
+		if !isRegexp(a) {
+			continue
+		}
+		if _, err := regexp.Compile(a[1 : len(a)-1]); err != nil {
+			return fmt.Errorf("invalid answer for %q: %w", p.Question, err)
+		}
+	}
+	return nil
+}
+
+// isRegexp reports whether the answer is a regular expression.
+func isRegexp(answer string) bool {
+	return len(answer) >= 2 && strings.HasPrefix(answer, "/") && strings.HasSuffix(answer, "/")
+}
@@ -0,0 +1,134 @@
+package quiz
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+)
+
+// ErrDuplicate is returned when a question is added to a bank twice.
+var ErrDuplicate = errors.New("duplicate question")
+
+// Problem is a pair of a question and its corresponding answer. Besides
+// that a problem may have a type other than free text, a hint the user
+// can ask for and an explanation shown after answering.
+type Problem struct {
+	Question string `json:"question" yaml:"question"`
+	Answer   string `json:"answer" yaml:"answer"`
+
+	Type        Type     `json:"type,omitempty" yaml:"type,omitempty"`
+	Options     []string `json:"options,omitempty" yaml:"options,omitempty"`
+	Hint        string   `json:"hint,omitempty" yaml:"hint,omitempty"`
+	Explanation string   `json:"explanation,omitempty" yaml:"explanation,omitempty"`
+
+	// Category and Difficulty allow to select problems of a bank for
+	// different training tracks, both are free text.
+	Category   string `json:"category,omitempty" yaml:"category,omitempty"`
+	Difficulty string `json:"difficulty,omitempty" yaml:"difficulty,omitempty"`
+
+	// Points is what a correct answer is worth, one if not set.
+	Points int `json:"points,omitempty" yaml:"points,omitempty"`
+}
+
+// Worth returns the points a correct answer is worth.
+func (p Problem) Worth() int {
+	if p.Points == 0 {
+		return 1
+	}
+	return p.Points
+}
+
+// record returns the record of the problem before it is answered.
+func (p Problem) record() Record {
+	return Record{
+		Question:    p.Question,
+		Expected:    p.Answer,
+		Category:    p.Category,
+		Points:      p.Worth(),
+		Explanation: p.Explanation,
+	}
+}
+
+// Bank holds all problems of a quiz in the order they were added.
+type Bank struct {
+	Problems []Problem
+
+	// questions maps each question to its position in Problems
+	questions map[string]int
+}
+
+// NewBank returns an empty bank.
+func NewBank() *Bank {
+	return &Bank{questions: make(map[string]int)}
+}
+
+// Add appends a problem to the bank. Adding a question which is already
+// part of the bank results in an error wrapping ErrDuplicate, an answer
+// with an invalid regular expression or not fitting the type of the
+// question is an error as well. A problem without type is a text problem.
+func (b *Bank) Add(p Problem) error {
+	if p.Type == "" {
+		p.Type = TypeText
+	}
+	if err := p.validate(); err != nil {
+		return err
+	}
+	if err := p.validateType(); err != nil {
+		return err
+	}
+	if p.Points < 0 {
+		return fmt.Errorf("negative points for %q", p.Question)
+	}
+	if b.questions == nil {
+		b.questions = make(map[string]int)
+	}
+	if _, ok := b.questions[p.Question]; ok {
+		return fmt.Errorf("%w %q", ErrDuplicate, p.Question)
+	}
+	b.questions[p.Question] = len(b.Problems)
+	b.Problems = append(b.Problems, p)
+	return nil
+}
+
+// Len returns the number of problems in the bank.
+func (b *Bank) Len() int {
+	return len(b.Problems)
+}
+
+// Points returns the points of all problems together.
+func (b *Bank) Points() int {
+	n := 0
+	for _, p := range b.Problems {
+		n += p.Worth()
+	}
+	return n
+}
+
+// Filter returns a new bank holding only the problems keep returns true
+// for, in the same order.
+func (b *Bank) Filter(keep func(Problem) bool) *Bank {
+	filtered := NewBank()
+	for _, p := range b.Problems {
+		if keep(p) {
+			// the problems come from a bank, so they can't be duplicates
+			filtered.Add(p)
+		}
+	}
+	return filtered
+}
+
+// Shuffle randomizes the order of the problems. The same seed always
+// results in the same order for a given bank, so a session can be
+// reproduced exactly.
+func (b *Bank) Shuffle(seed int64) {
+	r := rand.New(rand.NewSource(seed))
+	r.Shuffle(len(b.Problems), func(i, j int) {
+		b.Problems[i], b.Problems[j] = b.Problems[j], b.Problems[i]
+	})
+
+	// positions changed, so rebuild the question index
+	b.questions = make(map[string]int, len(b.Problems))
+	for i, p := range b.Problems {
+		b.questions[p.Question] = i
+	}
+}
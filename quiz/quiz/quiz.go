@@ -0,0 +1,214 @@
+package quiz
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// HintRequest is the answer asking for the hint of a problem instead of
+// answering it.
+const HintRequest = "?"
+
+// Quiz asks the problems of a bank one after another and checks the
+// answers read from In. Both the quiz as a whole and every single
+// question can be limited in time.
+type Quiz struct {
+	Bank *Bank
+
+	// Limit is the time budget for the whole quiz, zero means no limit.
+	Limit time.Duration
+
+	// PerQuestion is the time allowed to answer a single question, zero
+	// means no limit. A question running out of time counts as unanswered.
+	PerQuestion time.Duration
+
+	// Matcher compares the input with the expected answers, if nil Exact
+	// is used.
+	Matcher Matcher
+
+	// In is read line by line for the answers, the questions and all other
+	// messages to the user are written to Out.
+	In  io.Reader
+	Out io.Writer
+
+	// Clock is used for all timing, if nil the wall clock is used.
+	Clock Clock
+
+	// Resume continues an interrupted quiz from its checkpoint instead of
+	// starting over, Bank must hold the problems of the checkpoint.
+	Resume *Checkpoint
+}
+
+// input is a single line answered by the user, or the error reading it.
+type input struct {
+	text string
+	err  error
+}
+
+// Run asks all problems and returns the result. The quiz ends early if
+// the global time limit is reached or In runs out of answers, problems
+// not asked by then are recorded as unanswered.
+func (q *Quiz) Run() (*Result, error) {
+	return q.RunContext(context.Background())
+}
+
+// RunContext is like Run, but the quiz is also interrupted as soon as ctx
+// is done. The partial result of an interrupted quiz is returned without
+// error, a checkpoint to resume it later can be taken from it.
+func (q *Quiz) RunContext(ctx context.Context) (*Result, error) {
+	clock := q.clock()
+	started := clock.Now()
+	res := &Result{Started: started}
+	limit := q.Limit
+
+	// a resumed quiz picks up where it was interrupted, with the time
+	// spent so far taken off the limit
+	if cp := q.Resume; cp != nil {
+		if len(cp.Records) > q.Bank.Len() {
+			return nil, fmt.Errorf("checkpoint holds more records than there are problems")
+		}
+		res.Started = cp.Started
+		res.Records = append(res.Records, cp.Records...)
+		res.Elapsed = cp.Elapsed
+		limit -= cp.Elapsed
+	}
+	defer func() {
+		res.Elapsed += clock.Now().Sub(started)
+		res.fill(q.Bank)
+	}()
+
+	m := q.Matcher
+	if m == nil {
+		m = Exact
+	}
+
+	done := make(chan struct{})
+	defer close(done)
+	answers := q.read(done)
+
+	// a nil channel blocks forever, so without limit the case never fires
+	var expired <-chan time.Time
+	if q.Limit > 0 {
+		t := clock.NewTimer(limit)
+		defer t.Stop()
+		expired = t.C()
+	}
+
+	for _, p := range q.Bank.Problems[len(res.Records):] {
+		rec, end, err := q.ask(ctx, p, m, answers, expired)
+		if err != nil {
+			return res, err
+		}
+		switch end {
+		case expiredEnd:
+			res.Expired = true
+			return res, nil
+		case interruptedEnd:
+			res.Interrupted = true
+			return res, nil
+		case inputEnd:
+			return res, nil
+		}
+		res.Records = append(res.Records, rec)
+
+		if p.Explanation != "" {
+			fmt.Fprintf(q.Out, "%s\n", p.Explanation)
+		}
+	}
+	return res, nil
+}
+
+// ending tells why a quiz ended before all problems were asked.
+type ending int
+
+const (
+	noEnd          ending = iota // the quiz goes on
+	inputEnd                     // In ran out of answers
+	expiredEnd                   // the global time limit was reached
+	interruptedEnd               // the quiz was interrupted
+)
+
+// ask asks a single problem and waits for the answer. If the whole quiz
+// has to end, the reason is returned instead of a record.
+func (q *Quiz) ask(ctx context.Context, p Problem, m Matcher, answers <-chan input, expired <-chan time.Time) (Record, ending, error) {
+	clock := q.clock()
+	rec := p.record()
+	asked := clock.Now()
+
+	var timeout <-chan time.Time
+	if q.PerQuestion > 0 {
+		t := clock.NewTimer(q.PerQuestion)
+		defer t.Stop()
+		timeout = t.C()
+	}
+
+	// the prompt is written last, so once it shows up the question is all
+	// set and the clock is running
+	fmt.Fprint(q.Out, p.Prompt())
+
+	for {
+		select {
+		case in, ok := <-answers:
+			if !ok {
+				// no more input, leave the remaining questions unanswered
+				return Record{}, inputEnd, nil
+			}
+			if in.err != nil {
+				return Record{}, inputEnd, fmt.Errorf("error scanning user input: %w", in.err)
+			}
+
+			// asking for the hint is no answer, the clock keeps ticking
+			if strings.TrimSpace(in.text) == HintRequest && p.Hint != "" {
+				fmt.Fprintf(q.Out, "hint: %s\n%s", p.Hint, p.Prompt())
+				continue
+			}
+
+			rec.Given = in.text
+			rec.Answered = true
+			rec.Correct = p.Check(in.text, m)
+			rec.Duration = clock.Now().Sub(asked)
+			return rec, noEnd, nil
+		case <-timeout:
+			fmt.Fprintln(q.Out, "\ntime's up, next question")
+			rec.TimedOut = true
+			rec.Duration = q.PerQuestion
+			return rec, noEnd, nil
+		case <-expired:
+			fmt.Fprintln(q.Out, "\nTIMER EXPIRED!!")
+			return Record{}, expiredEnd, nil
+		case <-ctx.Done():
+			fmt.Fprintln(q.Out, "\nquiz interrupted")
+			return Record{}, interruptedEnd, nil
+		}
+	}
+}
+
+// read scans answers line by line from In in the background, so that
+// waiting for the user can be interrupted by the timers. The returned
+// channel is closed on end of input, reading stops as soon as done is
+// closed.
+func (q *Quiz) read(done <-chan struct{}) <-chan input {
+	answers := make(chan input)
+	go func() {
+		defer close(answers)
+		s := bufio.NewScanner(q.In)
+		for s.Scan() {
+			select {
+			case answers <- input{text: s.Text()}:
+			case <-done:
+				return
+			}
+		}
+		if err := s.Err(); err != nil {
+			select {
+			case answers <- input{err: err}:
+			case <-done:
+			}
+		}
+	}()
+	return answers
+}
@@ -0,0 +1,393 @@
+package quiz_test
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mbraunwarth/quiz/quiz"
+	"github.com/mbraunwarth/quiz/quiztest"
+)
+
+// newBank returns a bank of three problems worth one, two and three
+// points, the second one with a hint.
+func newBank(t *testing.T) *quiz.Bank {
+	t.Helper()
+	b := quiz.NewBank()
+	for _, p := range []quiz.Problem{
+		{Question: "5+5", Answer: "10", Category: "add"},
+		{Question: "7*3", Answer: "21", Hint: "three sevens", Category: "mul", Points: 2},
+		{Question: "9-4", Answer: "5", Category: "sub", Points: 3},
+	} {
+		if err := b.Add(p); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return b
+}
+
+func TestRun(t *testing.T) {
+	tests := []struct {
+		name        string
+		limit       time.Duration
+		perQuestion time.Duration
+		steps       []quiztest.Step
+		correct     int
+		answered    int
+		score       int
+		timedOut    []bool
+		expired     bool
+		output      string
+	}{
+		{
+			name:     "all correct",
+			steps:    []quiztest.Step{{Answer: "10"}, {Answer: "21"}, {Answer: "5"}},
+			correct:  3,
+			answered: 3,
+			score:    6,
+			timedOut: []bool{false, false, false},
+		},
+		{
+			name:     "wrong answer",
+			steps:    []quiztest.Step{{Answer: "10"}, {Answer: "20"}, {Answer: "5"}},
+			correct:  2,
+			answered: 3,
+			score:    4,
+			timedOut: []bool{false, false, false},
+		},
+		{
+			name:        "per question timeout",
+			perQuestion: 10 * time.Second,
+			steps: []quiztest.Step{
+				{Think: 2 * time.Second, Answer: "10"},
+				{Think: 11 * time.Second, Silent: true},
+				{Think: 2 * time.Second, Answer: "5"},
+			},
+			correct:  2,
+			answered: 2,
+			score:    4,
+			timedOut: []bool{false, true, false},
+			output:   "time's up, next question",
+		},
+		{
+			name:  "global expiry",
+			limit: 30 * time.Second,
+			steps: []quiztest.Step{
+				{Think: 5 * time.Second, Answer: "10"},
+				{Think: 30 * time.Second, Silent: true},
+			},
+			correct:  1,
+			answered: 1,
+			score:    1,
+			timedOut: []bool{false, false, false},
+			expired:  true,
+			output:   "TIMER EXPIRED!!",
+		},
+		{
+			name:     "hint request",
+			steps:    []quiztest.Step{{Answer: "10"}, {Answer: quiz.HintRequest}, {Answer: "21"}, {Answer: "5"}},
+			correct:  3,
+			answered: 3,
+			score:    6,
+			timedOut: []bool{false, false, false},
+			output:   "hint: three sevens",
+		},
+		{
+			name:     "input ends",
+			steps:    []quiztest.Step{{Answer: "10"}},
+			correct:  1,
+			answered: 1,
+			score:    1,
+			timedOut: []bool{false, false, false},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := &quiz.Quiz{Bank: newBank(t), Limit: tt.limit, PerQuestion: tt.perQuestion}
+			res, out, err := quiztest.Run(q, tt.steps...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if res.Total() != 3 {
+				t.Errorf("got %d records, want 3", res.Total())
+			}
+			if res.Correct() != tt.correct {
+				t.Errorf("got %d correct, want %d", res.Correct(), tt.correct)
+			}
+			if res.Answered() != tt.answered {
+				t.Errorf("got %d answered, want %d", res.Answered(), tt.answered)
+			}
+			if res.Score() != tt.score || res.MaxScore() != 6 {
+				t.Errorf("got score %d/%d, want %d/6", res.Score(), res.MaxScore(), tt.score)
+			}
+			if res.Expired != tt.expired {
+				t.Errorf("got expired %v, want %v", res.Expired, tt.expired)
+			}
+			for i, rec := range res.Records {
+				if rec.TimedOut != tt.timedOut[i] {
+					t.Errorf("record %d: got timed out %v, want %v", i, rec.TimedOut, tt.timedOut[i])
+				}
+				if rec.TimedOut && rec.Duration != tt.perQuestion {
+					t.Errorf("record %d: got duration %v, want %v", i, rec.Duration, tt.perQuestion)
+				}
+			}
+			if !strings.Contains(out, tt.output) {
+				t.Errorf("output %q does not contain %q", out, tt.output)
+			}
+		})
+	}
+}
+
+func TestRunDurations(t *testing.T) {
+	q := &quiz.Quiz{Bank: newBank(t)}
+	res, _, err := quiztest.Run(q,
+		quiztest.Step{Think: 3 * time.Second, Answer: "10"},
+		quiztest.Step{Think: 4 * time.Second, Answer: "21"},
+		quiztest.Step{Think: 5 * time.Second, Answer: "5"},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i, want := range []time.Duration{3 * time.Second, 4 * time.Second, 5 * time.Second} {
+		if got := res.Records[i].Duration; got != want {
+			t.Errorf("record %d: got duration %v, want %v", i, got, want)
+		}
+	}
+	if res.Elapsed != 12*time.Second {
+		t.Errorf("got elapsed %v, want 12s", res.Elapsed)
+	}
+	if !res.Started.Equal(quiztest.Start) {
+		t.Errorf("got started %v, want %v", res.Started, quiztest.Start)
+	}
+}
+
+// played returns the result of a quiz with one wrong answer and one timed
+// out question.
+func played(t *testing.T) *quiz.Result {
+	t.Helper()
+	q := &quiz.Quiz{Bank: newBank(t), PerQuestion: 10 * time.Second}
+	res, _, err := quiztest.Run(q,
+		quiztest.Step{Think: 1500 * time.Millisecond, Answer: "10"},
+		quiztest.Step{Think: 2 * time.Second, Answer: "20"},
+		quiztest.Step{Think: 10 * time.Second, Silent: true},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return res
+}
+
+func TestWriteJSON(t *testing.T) {
+	var b bytes.Buffer
+	if err := played(t).WriteJSON(&b); err != nil {
+		t.Fatal(err)
+	}
+
+	var report struct {
+		Started    time.Time            `json:"started"`
+		Total      int                  `json:"total"`
+		Answered   int                  `json:"answered"`
+		Correct    int                  `json:"correct"`
+		Score      int                  `json:"score"`
+		MaxScore   int                  `json:"max_score"`
+		Expired    bool                 `json:"expired"`
+		ElapsedMS  int64                `json:"elapsed_ms"`
+		Categories []quiz.CategoryScore `json:"categories"`
+		Questions  []struct {
+			Question string `json:"question"`
+			Given    string `json:"given"`
+			Expected string `json:"expected"`
+			Correct  bool   `json:"correct"`
+			Answered bool   `json:"answered"`
+			TimedOut bool   `json:"timed_out"`
+			TimeMS   int64  `json:"time_ms"`
+			Category string `json:"category"`
+			Points   int    `json:"points"`
+		} `json:"questions"`
+	}
+	if err := json.Unmarshal(b.Bytes(), &report); err != nil {
+		t.Fatalf("invalid json %q: %v", b.String(), err)
+	}
+
+	if report.Total != 3 || report.Answered != 2 || report.Correct != 1 {
+		t.Errorf("got total %d, answered %d, correct %d; want 3, 2, 1", report.Total, report.Answered, report.Correct)
+	}
+	if report.Score != 1 || report.MaxScore != 6 {
+		t.Errorf("got score %d/%d, want 1/6", report.Score, report.MaxScore)
+	}
+	if report.Expired || report.ElapsedMS != 13500 || !report.Started.Equal(quiztest.Start) {
+		t.Errorf("got expired %v, elapsed %dms, started %v", report.Expired, report.ElapsedMS, report.Started)
+	}
+	if len(report.Categories) != 3 {
+		t.Errorf("got %d categories, want 3", len(report.Categories))
+	}
+	if len(report.Questions) != 3 {
+		t.Fatalf("got %d questions, want 3", len(report.Questions))
+	}
+
+	q := report.Questions
+	if q[0].Question != "5+5" || q[0].Given != "10" || !q[0].Correct || q[0].TimeMS != 1500 || q[0].Category != "add" {
+		t.Errorf("question 1: got %+v", q[0])
+	}
+	if q[1].Given != "20" || q[1].Expected != "21" || q[1].Correct || !q[1].Answered || q[1].Points != 2 {
+		t.Errorf("question 2: got %+v", q[1])
+	}
+	if !q[2].TimedOut || q[2].Answered || q[2].TimeMS != 10000 {
+		t.Errorf("question 3: got %+v", q[2])
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	var b bytes.Buffer
+	if err := played(t).WriteCSV(&b); err != nil {
+		t.Fatal(err)
+	}
+
+	rows, err := csv.NewReader(&b).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	started := quiztest.Start.Format(time.RFC3339)
+	want := [][]string{
+		{"started", "question", "given", "expected", "correct", "answered", "timed_out", "time_ms", "category", "points"},
+		{started, "5+5", "10", "10", "true", "true", "false", "1500", "add", "1"},
+		{started, "7*3", "20", "21", "false", "true", "false", "2000", "mul", "2"},
+		{started, "9-4", "", "5", "false", "false", "true", "10000", "sub", "3"},
+	}
+	if len(rows) != len(want) {
+		t.Fatalf("got %d rows, want %d", len(rows), len(want))
+	}
+	for i := range want {
+		if strings.Join(rows[i], ",") != strings.Join(want[i], ",") {
+			t.Errorf("row %d: got %q, want %q", i, rows[i], want[i])
+		}
+	}
+}
+
+func TestSession(t *testing.T) {
+	clock := quiztest.NewClock(quiztest.Start)
+	q := &quiz.Quiz{Bank: newBank(t), PerQuestion: 10 * time.Second, Clock: clock}
+	s := q.Start()
+
+	if _, err := s.Answer(0, "10"); !errors.Is(err, quiz.ErrNotAsked) {
+		t.Errorf("answering before Next: got %v, want ErrNotAsked", err)
+	}
+
+	p, i, ok := s.Next()
+	if !ok || i != 0 || p.Question != "5+5" {
+		t.Fatalf("got question %d %q, %v", i, p.Question, ok)
+	}
+	if want := quiztest.Start.Add(10 * time.Second); !s.QuestionDeadline().Equal(want) {
+		t.Errorf("got question deadline %v, want %v", s.QuestionDeadline(), want)
+	}
+	if !s.Deadline().IsZero() {
+		t.Errorf("got deadline %v without limit", s.Deadline())
+	}
+	clock.Advance(2 * time.Second)
+	rec, err := s.Answer(i, "10")
+	if err != nil || !rec.Correct || rec.Duration != 2*time.Second {
+		t.Errorf("got %+v, %v", rec, err)
+	}
+	if _, err := s.Answer(i, "10"); !errors.Is(err, quiz.ErrNotAsked) {
+		t.Errorf("answering twice: got %v, want ErrNotAsked", err)
+	}
+
+	// the second question times out while nobody is looking
+	if _, i, _ = s.Next(); i != 1 {
+		t.Fatalf("got question %d, want 1", i)
+	}
+	clock.Advance(11 * time.Second)
+	if _, err := s.Answer(1, "21"); !errors.Is(err, quiz.ErrNotAsked) {
+		t.Errorf("answering timed out question: got %v, want ErrNotAsked", err)
+	}
+	if rec := s.Result().Records[1]; !rec.TimedOut || rec.Duration != 10*time.Second {
+		t.Errorf("got %+v, want timed out", rec)
+	}
+
+	if _, i, _ = s.Next(); i != 2 {
+		t.Fatalf("got question %d, want 2", i)
+	}
+	if _, err := s.Answer(2, "5"); err != nil {
+		t.Fatal(err)
+	}
+	if !s.Done() {
+		t.Error("session not done after last answer")
+	}
+	if _, _, ok := s.Next(); ok {
+		t.Error("got question after session is done")
+	}
+	if _, err := s.Answer(2, "5"); !errors.Is(err, quiz.ErrFinished) {
+		t.Errorf("answering finished session: got %v, want ErrFinished", err)
+	}
+
+	res := s.Result()
+	if res.Correct() != 2 || res.Score() != 4 || res.Elapsed != 13*time.Second {
+		t.Errorf("got %d correct, score %d, elapsed %v", res.Correct(), res.Score(), res.Elapsed)
+	}
+}
+
+func TestSessionLimit(t *testing.T) {
+	clock := quiztest.NewClock(quiztest.Start)
+	q := &quiz.Quiz{Bank: newBank(t), Limit: time.Minute, Clock: clock}
+	s := q.Start()
+
+	if want := quiztest.Start.Add(time.Minute); !s.Deadline().Equal(want) {
+		t.Errorf("got deadline %v, want %v", s.Deadline(), want)
+	}
+	_, i, _ := s.Next()
+	if _, err := s.Answer(i, "10"); err != nil {
+		t.Fatal(err)
+	}
+	s.Next()
+	clock.Advance(time.Minute)
+
+	if !s.Done() {
+		t.Fatal("session not done after limit")
+	}
+	res := s.Result()
+	if !res.Expired || res.Total() != 3 || res.Answered() != 1 {
+		t.Errorf("got expired %v, %d records, %d answered", res.Expired, res.Total(), res.Answered())
+	}
+}
+
+func TestSessionStop(t *testing.T) {
+	q := &quiz.Quiz{Bank: newBank(t), Clock: quiztest.NewClock(quiztest.Start)}
+	s := q.Start()
+	s.Next()
+	s.Stop()
+
+	if !s.Done() {
+		t.Error("session not done after Stop")
+	}
+	if res := s.Result(); res.Total() != 3 || res.Answered() != 0 || res.Expired {
+		t.Errorf("got %d records, %d answered, expired %v", res.Total(), res.Answered(), res.Expired)
+	}
+}
+
+func TestPrompt(t *testing.T) {
+	tests := []struct {
+		p    quiz.Problem
+		want string
+	}{
+		{quiz.Problem{Question: "5+5"}, "5+5 = "},
+		{quiz.Problem{Question: "5+5", Hint: "ten"}, "5+5 (? for a hint) = "},
+		{quiz.Problem{Question: "5 > 3", Type: quiz.TypeBool}, "5 > 3 (true/false) "},
+		{quiz.Problem{Question: "5 > 3", Type: quiz.TypeBool, Hint: "yes"}, "5 > 3 (true/false, ? for a hint) "},
+		{
+			quiz.Problem{Question: "5+5", Type: quiz.TypeChoice, Options: []string{"9", "10"}, Hint: "even"},
+			"5+5\n  A) 9\n  B) 10\nanswer (? for a hint): ",
+		},
+	}
+	for _, tt := range tests {
+		if got := tt.p.Prompt(); got != tt.want {
+			t.Errorf("got %q, want %q", got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,255 @@
+package quiz
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Record is the outcome of a single question.
+type Record struct {
+	Question string `json:"question"`
+	Given    string `json:"given"`
+	Expected string `json:"expected"`
+	Correct  bool   `json:"correct"`
+
+	// Answered is false if the question timed out or was never asked.
+	Answered bool `json:"answered"`
+
+	// TimedOut reports whether the question ran out of time.
+	TimedOut bool `json:"timed_out"`
+
+	// Duration is the time taken to answer the question.
+	Duration time.Duration `json:"duration"`
+
+	// Category is the category of the problem and Points what it is worth.
+	Category string `json:"category,omitempty"`
+	Points   int    `json:"points"`
+
+	// Explanation is the explanation of the problem, if any.
+	Explanation string `json:"explanation,omitempty"`
+}
+
+// Asked reports whether the question was asked at all.
+func (rec Record) Asked() bool {
+	return rec.Answered || rec.TimedOut
+}
+
+// Result holds the outcome of a quiz with one record per problem, in the
+// order the problems were asked.
+type Result struct {
+	Started time.Time
+	Records []Record
+
+	// Elapsed is the time spent on the quiz, across all runs of a resumed
+	// quiz.
+	Elapsed time.Duration
+
+	// Expired reports whether the quiz ended because Limit was reached.
+	Expired bool
+
+	// Interrupted reports whether the quiz was interrupted before the end.
+	Interrupted bool
+}
+
+// Total returns the number of problems of the quiz.
+func (r *Result) Total() int {
+	return len(r.Records)
+}
+
+// Answered returns the number of answered questions.
+func (r *Result) Answered() int {
+	n := 0
+	for _, rec := range r.Records {
+		if rec.Answered {
+			n++
+		}
+	}
+	return n
+}
+
+// Correct returns the number of correctly answered questions.
+func (r *Result) Correct() int {
+	n := 0
+	for _, rec := range r.Records {
+		if rec.Correct {
+			n++
+		}
+	}
+	return n
+}
+
+// Score returns the points of all correctly answered questions.
+func (r *Result) Score() int {
+	n := 0
+	for _, rec := range r.Records {
+		if rec.Correct {
+			n += rec.Points
+		}
+	}
+	return n
+}
+
+// MaxScore returns the points of all questions.
+func (r *Result) MaxScore() int {
+	n := 0
+	for _, rec := range r.Records {
+		n += rec.Points
+	}
+	return n
+}
+
+// CategoryScore is the part of a result belonging to a single category.
+type CategoryScore struct {
+	Category string `json:"category"`
+	Total    int    `json:"total"`
+	Correct  int    `json:"correct"`
+	Score    int    `json:"score"`
+	MaxScore int    `json:"max_score"`
+}
+
+// Categories breaks the result down by category in the order the
+// categories first appear. Problems without category are grouped under
+// the empty category.
+func (r *Result) Categories() []CategoryScore {
+	var scores []CategoryScore
+	index := make(map[string]int)
+	for _, rec := range r.Records {
+		i, ok := index[rec.Category]
+		if !ok {
+			i = len(scores)
+			index[rec.Category] = i
+			scores = append(scores, CategoryScore{Category: rec.Category})
+		}
+
+		cs := &scores[i]
+		cs.Total++
+		cs.MaxScore += rec.Points
+		if rec.Correct {
+			cs.Correct++
+			cs.Score += rec.Points
+		}
+	}
+	return scores
+}
+
+// fill appends unanswered records for all problems of b not yet
+// recorded, so a result always covers the whole bank.
+func (r *Result) fill(b *Bank) {
+	for _, p := range b.Problems[len(r.Records):] {
+		r.Records = append(r.Records, p.record())
+	}
+}
+
+// jsonRecord is the exported form of a Record.
+type jsonRecord struct {
+	Question string `json:"question"`
+	Given    string `json:"given"`
+	Expected string `json:"expected"`
+	Correct  bool   `json:"correct"`
+	Answered bool   `json:"answered"`
+	TimedOut bool   `json:"timed_out"`
+	TimeMS   int64  `json:"time_ms"`
+	Category string `json:"category,omitempty"`
+	Points   int    `json:"points"`
+}
+
+// WriteJSON writes the result including a summary and all records as
+// JSON to w.
+func (r *Result) WriteJSON(w io.Writer) error {
+	report := struct {
+		Started     time.Time       `json:"started"`
+		Total       int             `json:"total"`
+		Answered    int             `json:"answered"`
+		Correct     int             `json:"correct"`
+		Score       int             `json:"score"`
+		MaxScore    int             `json:"max_score"`
+		Expired     bool            `json:"expired"`
+		Interrupted bool            `json:"interrupted"`
+		ElapsedMS   int64           `json:"elapsed_ms"`
+		Categories  []CategoryScore `json:"categories"`
+		Questions   []jsonRecord    `json:"questions"`
+	}{
+		Started:     r.Started,
+		Total:       r.Total(),
+		Answered:    r.Answered(),
+		Correct:     r.Correct(),
+		Score:       r.Score(),
+		MaxScore:    r.MaxScore(),
+		Expired:     r.Expired,
+		Interrupted: r.Interrupted,
+		ElapsedMS:   r.Elapsed.Milliseconds(),
+		Categories:  r.Categories(),
+		Questions:   make([]jsonRecord, 0, len(r.Records)),
+	}
+	for _, rec := range r.Records {
+		report.Questions = append(report.Questions, jsonRecord{
+			Question: rec.Question,
+			Given:    rec.Given,
+			Expected: rec.Expected,
+			Correct:  rec.Correct,
+			Answered: rec.Answered,
+			TimedOut: rec.TimedOut,
+			TimeMS:   rec.Duration.Milliseconds(),
+			Category: rec.Category,
+			Points:   rec.Points,
+		})
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(report)
+}
+
+// WriteCSV writes one row per record to w, preceded by a header row.
+func (r *Result) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"started", "question", "given", "expected", "correct", "answered", "timed_out", "time_ms", "category", "points"})
+	started := r.Started.Format(time.RFC3339)
+	for _, rec := range r.Records {
+		cw.Write([]string{
+			started,
+			rec.Question,
+			rec.Given,
+			rec.Expected,
+			strconv.FormatBool(rec.Correct),
+			strconv.FormatBool(rec.Answered),
+			strconv.FormatBool(rec.TimedOut),
+			strconv.FormatInt(rec.Duration.Milliseconds(), 10),
+			rec.Category,
+			strconv.Itoa(rec.Points),
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteReport writes the result to the file at path, either as JSON or
+// CSV depending on the file extension.
+func (r *Result) WriteReport(path string) error {
+	var write func(io.Writer) error
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		write = r.WriteJSON
+	case ".csv":
+		write = r.WriteCSV
+	default:
+		return fmt.Errorf("unsupported report format %q, use .json or .csv", filepath.Ext(path))
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := write(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
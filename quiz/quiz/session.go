@@ -0,0 +1,172 @@
+package quiz
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	// ErrFinished is returned when answering a session which is over.
+	ErrFinished = errors.New("quiz is finished")
+
+	// ErrNotAsked is returned when answering a question which is not the
+	// current one, e.g. because it timed out in the meantime.
+	ErrNotAsked = errors.New("question is not asked")
+)
+
+// Session is a quiz played step by step instead of reading answers from
+// a stream, like a quiz served over HTTP. All time limits of the quiz are
+// enforced by the session itself whenever it is accessed. A Session is
+// not safe for concurrent use.
+type Session struct {
+	quiz    *Quiz
+	matcher Matcher
+	res     *Result
+	done    bool
+
+	// begun is the time the session was started and limit the time left
+	// from then on, which is less than the limit of the quiz if resumed
+	begun time.Time
+	limit time.Duration
+
+	// asked is the time the current question was handed out, zero if it
+	// was not handed out yet
+	asked time.Time
+}
+
+// Start begins a new session of the quiz. The global time limit starts
+// running right away, the time limit of each question starts as soon as
+// it is handed out by Next. Like Run, a session continues from the
+// checkpoint in Resume if there is one.
+func (q *Quiz) Start() *Session {
+	now := q.clock().Now()
+	s := &Session{
+		quiz:    q,
+		matcher: q.Matcher,
+		res:     &Result{Started: now},
+		begun:   now,
+		limit:   q.Limit,
+	}
+	if s.matcher == nil {
+		s.matcher = Exact
+	}
+	if cp := q.Resume; cp != nil && len(cp.Records) <= q.Bank.Len() {
+		s.res.Started = cp.Started
+		s.res.Records = append(s.res.Records, cp.Records...)
+		s.res.Elapsed = cp.Elapsed
+		s.limit -= cp.Elapsed
+	}
+	s.update()
+	return s
+}
+
+// Next returns the current question and its index, starting its timer if
+// it was not handed out before. ok is false once the session is over.
+func (s *Session) Next() (p Problem, index int, ok bool) {
+	s.update()
+	if s.done {
+		return Problem{}, 0, false
+	}
+	if s.asked.IsZero() {
+		s.asked = s.quiz.clock().Now()
+	}
+	index = len(s.res.Records)
+	return s.quiz.Bank.Problems[index], index, true
+}
+
+// Answer checks text as the answer to the question at index and records
+// it. Answering any other than the question handed out by the last call
+// to Next results in ErrNotAsked.
+func (s *Session) Answer(index int, text string) (Record, error) {
+	s.update()
+	if s.done {
+		return Record{}, ErrFinished
+	}
+	if index != len(s.res.Records) || s.asked.IsZero() {
+		return Record{}, ErrNotAsked
+	}
+
+	p := s.quiz.Bank.Problems[index]
+	rec := p.record()
+	rec.Given = text
+	rec.Answered = true
+	rec.Correct = p.Check(text, s.matcher)
+	rec.Duration = s.quiz.clock().Now().Sub(s.asked)
+	s.res.Records = append(s.res.Records, rec)
+	s.asked = time.Time{}
+	s.update()
+	return rec, nil
+}
+
+// Deadline returns the time the whole session ends, zero without limit.
+func (s *Session) Deadline() time.Time {
+	if s.quiz.Limit <= 0 {
+		return time.Time{}
+	}
+	return s.begun.Add(s.limit)
+}
+
+// QuestionDeadline returns the time the current question times out, zero
+// without limit or if the question was not handed out yet.
+func (s *Session) QuestionDeadline() time.Time {
+	if s.quiz.PerQuestion <= 0 || s.asked.IsZero() {
+		return time.Time{}
+	}
+	return s.asked.Add(s.quiz.PerQuestion)
+}
+
+// Done reports whether the session is over.
+func (s *Session) Done() bool {
+	s.update()
+	return s.done
+}
+
+// Stop ends the session early, leaving all questions not answered yet
+// unanswered. Stopping a session which is over already does nothing.
+func (s *Session) Stop() {
+	s.update()
+	if !s.done {
+		s.finish()
+	}
+}
+
+// Result returns the result so far, covering the whole bank once the
+// session is over.
+func (s *Session) Result() *Result {
+	s.update()
+	return s.res
+}
+
+// update applies the time limits, recording a timed out question as
+// unanswered and ending the session if the global limit is reached.
+func (s *Session) update() {
+	if s.done {
+		return
+	}
+
+	now := s.quiz.clock().Now()
+	if d := s.Deadline(); !d.IsZero() && !now.Before(d) {
+		s.res.Expired = true
+		s.finish()
+		return
+	}
+	if d := s.QuestionDeadline(); !d.IsZero() && !now.Before(d) {
+		p := s.quiz.Bank.Problems[len(s.res.Records)]
+		rec := p.record()
+		rec.TimedOut = true
+		rec.Duration = s.quiz.PerQuestion
+		s.res.Records = append(s.res.Records, rec)
+		s.asked = time.Time{}
+	}
+	if len(s.res.Records) == s.quiz.Bank.Len() {
+		s.finish()
+	}
+}
+
+// finish ends the session.
+func (s *Session) finish() {
+	s.done = true
+	s.asked = time.Time{}
+	s.res.Elapsed += s.quiz.clock().Now().Sub(s.begun)
+	s.res.fill(s.quiz.Bank)
+}
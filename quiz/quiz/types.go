@@ -0,0 +1,138 @@
+package quiz
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Type is the kind of a question, determining how it is shown and how
+// answers are checked.
+type Type string
+
+const (
+	// TypeText is a free text question, the default.
+	TypeText Type = "text"
+
+	// TypeChoice is a multiple choice question. The options are lettered
+	// A, B, C and so on, the answer is the letter or the text of the
+	// correct option and users may answer with either.
+	TypeChoice Type = "choice"
+
+	// TypeBool is a true or false question, yes and no work as well.
+	TypeBool Type = "bool"
+
+	// TypeNumeric is a question with a number as answer, which is always
+	// compared numerically.
+	TypeNumeric Type = "numeric"
+)
+
+// DefaultTolerance is the tolerance numeric questions are checked with
+// unless the numeric matcher is used with a tolerance of its own.
+const DefaultTolerance = 1e-9
+
+// ParseType returns the type with the given name, an empty name is
+// TypeText.
+func ParseType(name string) (Type, error) {
+	switch t := Type(strings.ToLower(strings.TrimSpace(name))); t {
+	case "", TypeText:
+		return TypeText, nil
+	case TypeChoice, TypeBool, TypeNumeric:
+		return t, nil
+	}
+	return "", fmt.Errorf("unknown question type %q, use text, choice, bool or numeric", name)
+}
+
+// Prompt returns the question as shown to the user, including the
+// lettered options of a multiple choice question and how to ask for the
+// hint, if there is one.
+func (p Problem) Prompt() string {
+	hint := ""
+	if p.Hint != "" {
+		hint = " (" + HintRequest + " for a hint)"
+	}
+
+	switch p.Type {
+	case TypeChoice:
+		var b strings.Builder
+		b.WriteString(p.Question)
+		b.WriteString("\n")
+		for i, o := range p.Options {
+			fmt.Fprintf(&b, "  %c) %s\n", 'A'+i, o)
+		}
+		b.WriteString("answer" + hint + ": ")
+		return b.String()
+	case TypeBool:
+		if p.Hint != "" {
+			return p.Question + " (true/false, " + HintRequest + " for a hint) "
+		}
+		return p.Question + " (true/false) "
+	}
+	return p.Question + hint + " = "
+}
+
+// choice resolves the letter of an option to the option itself, anything
+// else is returned trimmed as is.
+func (p Problem) choice(s string) string {
+	s = strings.TrimSpace(s)
+	if len(s) == 1 {
+		i := int(strings.ToUpper(s)[0]) - 'A'
+		if i >= 0 && i < len(p.Options) {
+			return p.Options[i]
+		}
+	}
+	return s
+}
+
+// parseBool parses the usual ways to say true or false.
+func parseBool(s string) (value, ok bool) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "t", "true", "y", "yes", "1":
+		return true, true
+	case "f", "false", "n", "no", "0":
+		return false, true
+	}
+	return false, false
+}
+
+// validateType makes sure the answer fits the type of the problem.
+func (p Problem) validateType() error {
+	if _, err := ParseType(string(p.Type)); err != nil {
+		return err
+	}
+
+	switch p.Type {
+	case TypeChoice:
+		if len(p.Options) < 2 {
+			return fmt.Errorf("multiple choice question %q needs at least two options", p.Question)
+		}
+		for _, a := range p.Accepted() {
+			if !isRegexp(a) && !p.isOption(p.choice(a)) {
+				return fmt.Errorf("answer %q of %q is none of its options", a, p.Question)
+			}
+		}
+	case TypeBool:
+		for _, a := range p.Accepted() {
+			if _, ok := parseBool(a); !ok {
+				return fmt.Errorf("answer %q of true/false question %q is neither true nor false", a, p.Question)
+			}
+		}
+	case TypeNumeric:
+		for _, a := range p.Accepted() {
+			if _, err := strconv.ParseFloat(strings.TrimSpace(a), 64); err != nil && !isRegexp(a) {
+				return fmt.Errorf("answer %q of numeric question %q is no number", a, p.Question)
+			}
+		}
+	}
+	return nil
+}
+
+// isOption reports whether s is one of the options.
+func (p Problem) isOption(s string) bool {
+	for _, o := range p.Options {
+		if strings.EqualFold(o, s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,88 @@
+package quiztest
+
+import (
+	"sync"
+	"time"
+
+	"github.com/mbraunwarth/quiz/quiz"
+)
+
+// Clock is a fake quiz.Clock which only moves when advanced, so timeouts
+// can be tested without waiting for them. A Clock is safe for concurrent
+// use.
+type Clock struct {
+	mu     sync.Mutex
+	now    time.Time
+	timers []*timer
+}
+
+// NewClock returns a clock set to the given time.
+func NewClock(now time.Time) *Clock {
+	return &Clock{now: now}
+}
+
+// Now implements the quiz.Clock interface.
+func (c *Clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// NewTimer implements the quiz.Clock interface.
+func (c *Clock) NewTimer(d time.Duration) quiz.Timer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	t := &timer{clock: c, at: c.now.Add(d), c: make(chan time.Time, 1)}
+	c.timers = append(c.timers, t)
+	c.fire()
+	return t
+}
+
+// Advance moves the clock forward by d, firing all timers which are due
+// by then.
+func (c *Clock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+	c.fire()
+}
+
+// fire sends the current time on all due timers and drops them, c.mu must
+// be held.
+func (c *Clock) fire() {
+	pending := c.timers[:0]
+	for _, t := range c.timers {
+		if t.at.After(c.now) {
+			pending = append(pending, t)
+			continue
+		}
+		t.c <- c.now
+	}
+	c.timers = pending
+}
+
+// timer is a timer of the fake clock.
+type timer struct {
+	clock *Clock
+	at    time.Time
+	c     chan time.Time
+}
+
+func (t *timer) C() <-chan time.Time {
+	return t.c
+}
+
+func (t *timer) Stop() bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+
+	for i, other := range t.clock.timers {
+		if other == t {
+			t.clock.timers = append(t.clock.timers[:i], t.clock.timers[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,136 @@
+// Package quiztest provides utilities for testing code built on the quiz
+// package: a fake clock and a harness playing a quiz with scripted
+// answers, so scores, timeouts and reports can be checked without a user
+// and without waiting for timers.
+//
+// See the tests of the quiz package for table-driven tests using them.
+package quiztest
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mbraunwarth/quiz/quiz"
+)
+
+// Start is the time the fake clock of Run starts at.
+var Start = time.Date(2021, time.January, 1, 12, 0, 0, 0, time.UTC)
+
+// Step is a single scripted action of the user, answering the question
+// asked last.
+type Step struct {
+	// Think is the time passing before the user answers.
+	Think time.Duration
+
+	// Answer is the line entered by the user.
+	Answer string
+
+	// Silent steps only let time pass without answering, to let a
+	// question or the whole quiz time out. Think must reach the time
+	// limit, otherwise the quiz keeps waiting for an answer.
+	Silent bool
+}
+
+// Run plays q with the given steps as answers and a fake clock, which
+// moves only by the time the steps think. It returns the result and
+// everything written to the user. q itself is left untouched.
+//
+// Every step waits for the next prompt before it answers, so each step
+// belongs to exactly one question. Once the steps run out the input ends,
+// leaving the remaining questions unanswered.
+func Run(q *quiz.Quiz, steps ...Step) (*quiz.Result, string, error) {
+	out := &promptWriter{}
+	out.cond = sync.NewCond(&out.mu)
+	clock := NewClock(Start)
+	script := &script{steps: steps, out: out, clock: clock}
+
+	run := *q
+	run.In, run.Out, run.Clock = script, out, clock
+
+	res, err := run.Run()
+	out.close()
+	return res, out.String(), err
+}
+
+// promptWriter records everything written to the user and counts the
+// prompts, which are the writes not ending in a newline.
+type promptWriter struct {
+	mu      sync.Mutex
+	cond    *sync.Cond
+	buf     bytes.Buffer
+	prompts int
+	closed  bool
+}
+
+func (w *promptWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if len(p) > 0 && !bytes.HasSuffix(p, []byte("\n")) {
+		w.prompts++
+		w.cond.Broadcast()
+	}
+	return w.buf.Write(p)
+}
+
+func (w *promptWriter) String() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.String()
+}
+
+// waitPrompt blocks until more than n prompts were written and reports
+// whether this happened before the quiz was over.
+func (w *promptWriter) waitPrompt(n int) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for w.prompts <= n && !w.closed {
+		w.cond.Wait()
+	}
+	return w.prompts > n
+}
+
+// close marks the quiz as over, releasing the script.
+func (w *promptWriter) close() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.closed = true
+	w.cond.Broadcast()
+}
+
+// script is the input of the quiz, answering according to the steps.
+type script struct {
+	steps []Step
+	out   *promptWriter
+	clock *Clock
+
+	// prompts is the number of prompts answered so far
+	prompts int
+
+	// pending holds the rest of an answer not read yet
+	pending *strings.Reader
+}
+
+func (s *script) Read(p []byte) (int, error) {
+	for s.pending == nil || s.pending.Len() == 0 {
+		if len(s.steps) == 0 {
+			return 0, io.EOF
+		}
+		step := s.steps[0]
+		s.steps = s.steps[1:]
+
+		if !s.out.waitPrompt(s.prompts) {
+			return 0, io.EOF
+		}
+		s.prompts++
+		s.clock.Advance(step.Think)
+
+		if !step.Silent {
+			s.pending = strings.NewReader(step.Answer + "\n")
+		}
+	}
+	return s.pending.Read(p)
+}
@@ -0,0 +1,205 @@
+// Package remote fetches problem files over HTTP. Files are kept in a
+// local cache and revalidated with ETag and If-Modified-Since on every
+// fetch, so unchanged files are not downloaded again and the cached copy
+// keeps working while offline.
+package remote
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// IsURL reports whether the problems location s is an http(s) URL rather
+// than a local file.
+func IsURL(s string) bool {
+	return strings.HasPrefix(s, "http://") || strings.HasPrefix(s, "https://")
+}
+
+// OfflineError is returned along with the path of the cached copy if a
+// file could not be fetched, but was cached before.
+type OfflineError struct {
+	URL string
+	Err error
+}
+
+func (e *OfflineError) Error() string {
+	return fmt.Sprintf("using cached copy of %s: %s", e.URL, e.Err)
+}
+
+func (e *OfflineError) Unwrap() error {
+	return e.Err
+}
+
+// Fetcher fetches problem files into its cache directory.
+type Fetcher struct {
+	// Dir is the cache directory, it is created if missing.
+	Dir string
+
+	// Client is used for all requests, http.DefaultClient if nil.
+	Client *http.Client
+}
+
+// meta is what is remembered about a cached file to revalidate it.
+type meta struct {
+	URL string `json:"url"`
+
+	// File is the name of the cached copy within the cache directory,
+	// which is shared with other files, like the practice history.
+	File         string `json:"file"`
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"last_modified,omitempty"`
+}
+
+// Fetch returns the path of the local copy of the file at rawURL, which is
+// downloaded unless the cached copy is still up to date. The local copy
+// has the extension of the URL path, or one matching the content type,
+// so it can be loaded like any other problems file.
+//
+// If the server can't be reached or fails, the cached copy is used if
+// there is one, the returned error is an *OfflineError then.
+func (f *Fetcher) Fetch(rawURL string) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(f.Dir, 0755); err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256([]byte(rawURL))
+	base := filepath.Join(f.Dir, hex.EncodeToString(sum[:8]))
+	cached, m := f.cached(base)
+
+	req, err := http.NewRequest(http.MethodGet, rawURL, nil)
+	if err != nil {
+		return "", err
+	}
+	if cached != "" {
+		if m.ETag != "" {
+			req.Header.Set("If-None-Match", m.ETag)
+		}
+		if m.LastModified != "" {
+			req.Header.Set("If-Modified-Since", m.LastModified)
+		}
+	}
+
+	resp, err := f.client().Do(req)
+	if err != nil {
+		return offline(rawURL, cached, err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotModified && cached != "":
+		return cached, nil
+	case resp.StatusCode >= 500:
+		return offline(rawURL, cached, fmt.Errorf("server responded %s", resp.Status))
+	case resp.StatusCode != http.StatusOK:
+		return "", fmt.Errorf("error fetching %s: %s", rawURL, resp.Status)
+	}
+
+	local := base + extension(u, resp.Header.Get("Content-Type"))
+	if err := writeFile(local, resp.Body); err != nil {
+		return offline(rawURL, cached, err)
+	}
+
+	// the extension may have changed, leaving the old copy behind
+	if cached != "" && cached != local {
+		os.Remove(cached)
+	}
+
+	m = meta{
+		URL:          rawURL,
+		File:         filepath.Base(local),
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+	}
+	content, err := json.Marshal(m)
+	if err != nil {
+		return "", err
+	}
+	if err := ioutil.WriteFile(base+".meta", content, 0644); err != nil {
+		return "", err
+	}
+	return local, nil
+}
+
+func (f *Fetcher) client() *http.Client {
+	if f.Client == nil {
+		return http.DefaultClient
+	}
+	return f.Client
+}
+
+// cached returns the path and meta data of the cached copy with the given
+// base name, the path is empty if there is none.
+func (f *Fetcher) cached(base string) (string, meta) {
+	var m meta
+	content, err := ioutil.ReadFile(base + ".meta")
+	if err != nil || json.Unmarshal(content, &m) != nil || m.File == "" {
+		return "", meta{}
+	}
+
+	p := filepath.Join(f.Dir, filepath.Base(m.File))
+	if _, err := os.Stat(p); err != nil {
+		return "", meta{}
+	}
+	return p, m
+}
+
+// offline falls back to the cached copy if there is one.
+func offline(rawURL, cached string, err error) (string, error) {
+	if cached == "" {
+		return "", fmt.Errorf("error fetching %s: %w", rawURL, err)
+	}
+	return cached, &OfflineError{URL: rawURL, Err: err}
+}
+
+// extension returns the file extension for the fetched file, taken from
+// the URL path if it is one of a problems file or else from the content
+// type, .csv by default.
+func extension(u *url.URL, contentType string) string {
+	switch ext := strings.ToLower(path.Ext(u.Path)); ext {
+	case ".csv", ".json", ".yaml", ".yml":
+		return ext
+	}
+
+	t, _, _ := mime.ParseMediaType(contentType)
+	switch {
+	case strings.HasSuffix(t, "json"):
+		return ".json"
+	case strings.HasSuffix(t, "yaml"):
+		return ".yaml"
+	}
+	return ".csv"
+}
+
+// writeFile writes r to the file at path, replacing it atomically so a
+// failed download never leaves a broken copy behind.
+func writeFile(path string, r io.Reader) error {
+	tmp, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
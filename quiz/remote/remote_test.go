@@ -0,0 +1,222 @@
+package remote_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/mbraunwarth/quiz/practice"
+	"github.com/mbraunwarth/quiz/quiz"
+	"github.com/mbraunwarth/quiz/remote"
+)
+
+// server serves a single problems file with an ETag, counting the full
+// downloads. Its body, content type and status can be changed at any time.
+type server struct {
+	mu          sync.Mutex
+	body        string
+	etag        string
+	contentType string
+	status      int
+	downloads   int
+}
+
+func (s *server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.status != 0 {
+		w.WriteHeader(s.status)
+		return
+	}
+	if r.Header.Get("If-None-Match") == s.etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	s.downloads++
+	w.Header().Set("ETag", s.etag)
+	if s.contentType != "" {
+		w.Header().Set("Content-Type", s.contentType)
+	}
+	w.Write([]byte(s.body))
+}
+
+func (s *server) set(f func(s *server)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	f(s)
+}
+
+const problemsJSON = `[{"question": "5+5", "answer": "10"}]`
+
+// fetch fetches url and fails the test on any error.
+func fetch(t *testing.T, f *remote.Fetcher, url string) string {
+	t.Helper()
+	local, err := f.Fetch(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return local
+}
+
+// load loads the problems at path and fails the test if there are not
+// exactly n.
+func load(t *testing.T, path string, n int) {
+	t.Helper()
+	b, err := quiz.LoadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.Len() != n {
+		t.Fatalf("got %d problems, want %d", b.Len(), n)
+	}
+}
+
+func TestFetchRevalidates(t *testing.T) {
+	s := &server{body: problemsJSON, etag: `"v1"`}
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+
+	f := &remote.Fetcher{Dir: t.TempDir()}
+	url := ts.URL + "/problems.json"
+
+	first := fetch(t, f, url)
+	if filepath.Ext(first) != ".json" {
+		t.Errorf("got %s, want a .json file", first)
+	}
+	load(t, first, 1)
+
+	// the practice history lives right next to the cached copy
+	history := practice.PathFor(first)
+	if err := ioutil.WriteFile(history, []byte(`{"users": {}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	second := fetch(t, f, url)
+	if second != first {
+		t.Errorf("revalidated copy is %s, want %s", second, first)
+	}
+	load(t, second, 1)
+	if s.downloads != 1 {
+		t.Errorf("got %d downloads, want 1", s.downloads)
+	}
+
+	// a changed file is downloaded again
+	s.set(func(s *server) {
+		s.body = `[{"question": "5+5", "answer": "10"}, {"question": "7+3", "answer": "10"}]`
+		s.etag = `"v2"`
+	})
+	load(t, fetch(t, f, url), 2)
+	if s.downloads != 2 {
+		t.Errorf("got %d downloads, want 2", s.downloads)
+	}
+	if _, err := os.Stat(history); err != nil {
+		t.Errorf("history is gone: %v", err)
+	}
+}
+
+func TestFetchExtensionChange(t *testing.T) {
+	s := &server{body: problemsJSON, etag: `"v1"`, contentType: "application/json"}
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+
+	f := &remote.Fetcher{Dir: t.TempDir()}
+	first := fetch(t, f, ts.URL+"/problems")
+	if filepath.Ext(first) != ".json" {
+		t.Fatalf("got %s, want a .json file", first)
+	}
+	history := practice.PathFor(first)
+	if err := ioutil.WriteFile(history, []byte(`{"users": {}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	s.set(func(s *server) {
+		s.body = "5+5,10\n"
+		s.etag = `"v2"`
+		s.contentType = "text/csv"
+	})
+	second := fetch(t, f, ts.URL+"/problems")
+	if filepath.Ext(second) != ".csv" {
+		t.Fatalf("got %s, want a .csv file", second)
+	}
+	load(t, second, 1)
+
+	if _, err := os.Stat(first); !os.IsNotExist(err) {
+		t.Errorf("old copy %s was not removed: %v", first, err)
+	}
+	if _, err := os.Stat(history); err != nil {
+		t.Errorf("history is gone: %v", err)
+	}
+}
+
+func TestFetchExtension(t *testing.T) {
+	tests := []struct {
+		path        string
+		contentType string
+		body        string
+		want        string
+	}{
+		{"/problems.csv", "application/json", "5+5,10\n", ".csv"},
+		{"/problems.YML", "", "- question: 5+5\n  answer: 10\n", ".yml"},
+		{"/get.php?f=problems.csv", "application/json", problemsJSON, ".json"},
+		{"/problems.txt", "text/plain", "5+5,10\n", ".csv"},
+		{"/problems", "application/x-yaml", "- question: 5+5\n  answer: 10\n", ".yaml"},
+	}
+	for _, tt := range tests {
+		s := &server{body: tt.body, etag: `"v1"`, contentType: tt.contentType}
+		ts := httptest.NewServer(s)
+
+		f := &remote.Fetcher{Dir: t.TempDir()}
+		local := fetch(t, f, ts.URL+tt.path)
+		if filepath.Ext(local) != tt.want {
+			t.Errorf("%s: got %s, want a %s file", tt.path, local, tt.want)
+		}
+		load(t, local, 1)
+		ts.Close()
+	}
+}
+
+func TestFetchOffline(t *testing.T) {
+	s := &server{body: problemsJSON, etag: `"v1"`}
+	ts := httptest.NewServer(s)
+
+	f := &remote.Fetcher{Dir: t.TempDir()}
+	url := ts.URL + "/problems.json"
+	cached := fetch(t, f, url)
+
+	// a failing server falls back to the cached copy
+	s.set(func(s *server) { s.status = http.StatusServiceUnavailable })
+	local, err := f.Fetch(url)
+	var offline *remote.OfflineError
+	if !errors.As(err, &offline) || local != cached {
+		t.Errorf("got %s, %v; want %s and an OfflineError", local, err, cached)
+	}
+
+	// so does a server which is gone
+	ts.Close()
+	local, err = f.Fetch(url)
+	if !errors.As(err, &offline) || local != cached {
+		t.Errorf("got %s, %v; want %s and an OfflineError", local, err, cached)
+	}
+	load(t, local, 1)
+
+	// without cached copy there is nothing to fall back to
+	if local, err := f.Fetch(ts.URL + "/other.json"); err == nil || errors.As(err, &offline) {
+		t.Errorf("got %s, %v; want an error", local, err)
+	}
+}
+
+func TestFetchNotFound(t *testing.T) {
+	ts := httptest.NewServer(http.NotFoundHandler())
+	defer ts.Close()
+
+	f := &remote.Fetcher{Dir: t.TempDir()}
+	if local, err := f.Fetch(ts.URL + "/problems.csv"); err == nil {
+		t.Errorf("got %s, want an error", local)
+	}
+}
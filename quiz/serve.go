@@ -0,0 +1,26 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"net/http"
+
+	"github.com/mbraunwarth/quiz/server"
+)
+
+// serve runs the quiz as a web server, every visitor plays their own
+// session of the same problems in the browser.
+func serve(args []string) {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	addr := fs.String("addr", ":8080", "address to listen on")
+	quizFlags(fs)
+	fs.Parse(args)
+
+	q, err := newQuiz(fs)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	log.Printf("serving quiz with %d problems on %s", q.Bank.Len(), *addr)
+	log.Fatal(http.ListenAndServe(*addr, server.New(q)))
+}
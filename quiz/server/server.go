@@ -0,0 +1,287 @@
+package server
+
+import (
+	"crypto/rand"
+	"embed"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"html/template"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mbraunwarth/quiz/quiz"
+)
+
+//go:embed tmpl/index.html
+var tmplFS embed.FS
+
+var index = template.Must(template.ParseFS(tmplFS, "tmpl/index.html"))
+
+// sessionTTL is how long a session is kept after it was started.
+const sessionTTL = 24 * time.Hour
+
+// Server serves a quiz over HTTP. Every visitor plays their own session
+// of the same quiz, all time limits are enforced on the server.
+//
+// Routes:
+//
+//	GET  /                              HTML front end
+//	POST /api/sessions                  start a new session
+//	GET  /api/sessions/{id}             state and result of a session
+//	GET  /api/sessions/{id}/question    the current question
+//	POST /api/sessions/{id}/answer      answer the current question
+type Server struct {
+	quiz *quiz.Quiz
+
+	mu       sync.Mutex
+	sessions map[string]*quiz.Session
+}
+
+// New returns a server for the given quiz.
+func New(q *quiz.Quiz) *Server {
+	return &Server{quiz: q, sessions: make(map[string]*quiz.Session)}
+}
+
+// ServeHTTP implements the http.Handler interface.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == "/" {
+		s.serveIndex(w, r)
+		return
+	}
+
+	// split /api/sessions/{id}/{action} into its parts
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if len(parts) < 2 || parts[0] != "api" || parts[1] != "sessions" || len(parts) > 4 {
+		writeError(w, http.StatusNotFound, "not found")
+		return
+	}
+
+	switch {
+	case len(parts) == 2:
+		if !allow(w, r, http.MethodPost) {
+			return
+		}
+		s.createSession(w)
+	case len(parts) == 3:
+		if !allow(w, r, http.MethodGet) {
+			return
+		}
+		s.withSession(w, parts[2], s.sessionState)
+	case parts[3] == "question":
+		if !allow(w, r, http.MethodGet) {
+			return
+		}
+		s.withSession(w, parts[2], s.question)
+	case parts[3] == "answer":
+		if !allow(w, r, http.MethodPost) {
+			return
+		}
+		// read the body before taking the lock, so a slow client doesn't
+		// hold up everyone else
+		var req answerRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid request body")
+			return
+		}
+		s.withSession(w, parts[2], func(w http.ResponseWriter, sess *quiz.Session) {
+			s.answer(w, req, sess)
+		})
+	default:
+		writeError(w, http.StatusNotFound, "not found")
+	}
+}
+
+func (s *Server) serveIndex(w http.ResponseWriter, r *http.Request) {
+	if !allow(w, r, http.MethodGet) {
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := index.Execute(w, nil); err != nil {
+		log.Printf("error executing template: %s", err)
+	}
+}
+
+func (s *Server) createSession(w http.ResponseWriter) {
+	id, err := newID()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "could not create session")
+		return
+	}
+
+	s.mu.Lock()
+	s.prune()
+	sess := s.quiz.Start()
+	s.sessions[id] = sess
+	state := s.stateOf(id, sess)
+	s.mu.Unlock()
+
+	writeJSON(w, http.StatusCreated, state)
+}
+
+func (s *Server) sessionState(w http.ResponseWriter, sess *quiz.Session) {
+	writeJSON(w, http.StatusOK, s.stateOf("", sess))
+}
+
+func (s *Server) question(w http.ResponseWriter, sess *quiz.Session) {
+	p, i, ok := sess.Next()
+	if !ok {
+		writeJSON(w, http.StatusOK, questionResponse{Done: true})
+		return
+	}
+	writeJSON(w, http.StatusOK, questionResponse{
+		Type:                p.Type,
+		Options:             p.Options,
+		Hint:                p.Hint,
+		Index:               i,
+		Total:               s.quiz.Bank.Len(),
+		Question:            p.Question,
+		RemainingMS:         remaining(sess.Deadline()),
+		QuestionRemainingMS: remaining(sess.QuestionDeadline()),
+	})
+}
+
+// answerRequest is the JSON representation of an answer.
+type answerRequest struct {
+	Index  int    `json:"index"`
+	Answer string `json:"answer"`
+}
+
+func (s *Server) answer(w http.ResponseWriter, req answerRequest, sess *quiz.Session) {
+	rec, err := sess.Answer(req.Index, req.Answer)
+	switch {
+	case errors.Is(err, quiz.ErrFinished):
+		writeError(w, http.StatusConflict, err.Error())
+		return
+	case errors.Is(err, quiz.ErrNotAsked):
+		writeError(w, http.StatusConflict, "question timed out or was not asked")
+		return
+	case err != nil:
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, struct {
+		Correct     bool   `json:"correct"`
+		Expected    string `json:"expected"`
+		Explanation string `json:"explanation,omitempty"`
+		TimeMS      int64  `json:"time_ms"`
+		Done        bool   `json:"done"`
+	}{
+		rec.Correct,
+		rec.Expected,
+		s.quiz.Bank.Problems[req.Index].Explanation,
+		rec.Duration.Milliseconds(),
+		sess.Done(),
+	})
+}
+
+// withSession looks up the session with the given id and calls fn with
+// it while holding the lock.
+func (s *Server) withSession(w http.ResponseWriter, id string, fn func(http.ResponseWriter, *quiz.Session)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sess, ok := s.sessions[id]
+	if !ok {
+		writeError(w, http.StatusNotFound, "unknown session")
+		return
+	}
+	fn(w, sess)
+}
+
+// prune drops all sessions older than sessionTTL, s.mu must be held.
+func (s *Server) prune() {
+	for id, sess := range s.sessions {
+		if time.Since(sess.Result().Started) > sessionTTL {
+			delete(s.sessions, id)
+		}
+	}
+}
+
+// state is the JSON representation of a session.
+type state struct {
+	ID          string `json:"id,omitempty"`
+	Total       int    `json:"total"`
+	Answered    int    `json:"answered"`
+	Correct     int    `json:"correct"`
+	Score       int    `json:"score"`
+	MaxScore    int    `json:"max_score"`
+	Done        bool   `json:"done"`
+	Expired     bool   `json:"expired"`
+	RemainingMS int64  `json:"remaining_ms,omitempty"`
+}
+
+func (s *Server) stateOf(id string, sess *quiz.Session) state {
+	res := sess.Result()
+	return state{
+		ID:          id,
+		Total:       s.quiz.Bank.Len(),
+		Answered:    res.Answered(),
+		Correct:     res.Correct(),
+		Score:       res.Score(),
+		MaxScore:    s.quiz.Bank.Points(),
+		Done:        sess.Done(),
+		Expired:     res.Expired,
+		RemainingMS: remaining(sess.Deadline()),
+	}
+}
+
+// questionResponse is the JSON representation of the current question.
+type questionResponse struct {
+	Done                bool      `json:"done"`
+	Index               int       `json:"index"`
+	Total               int       `json:"total"`
+	Question            string    `json:"question,omitempty"`
+	Type                quiz.Type `json:"type,omitempty"`
+	Options             []string  `json:"options,omitempty"`
+	Hint                string    `json:"hint,omitempty"`
+	RemainingMS         int64     `json:"remaining_ms,omitempty"`
+	QuestionRemainingMS int64     `json:"question_remaining_ms,omitempty"`
+}
+
+// remaining returns the milliseconds left until the deadline, zero if
+// there is none.
+func remaining(deadline time.Time) int64 {
+	if deadline.IsZero() {
+		return 0
+	}
+	return time.Until(deadline).Milliseconds()
+}
+
+// allow writes an error response and returns false if the request does
+// not use the given method.
+func allow(w http.ResponseWriter, r *http.Request, method string) bool {
+	if r.Method != method {
+		w.Header().Set("Allow", method)
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return false
+	}
+	return true
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("error encoding response: %s", err)
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, msg string) {
+	writeJSON(w, status, struct {
+		Error string `json:"error"`
+	}{msg})
+}
+
+// newID returns a random session id.
+func newID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -1,11 +1,16 @@
+//go:build ignore
+// +build ignore
+
+// simple.go is the bare bones version of the quiz without any timer,
+// run it via `go run simple.go`.
 package main
 
 import (
-	"encoding/csv"
 	"flag"
 	"fmt"
 	"log"
-	"os"
+
+	"github.com/mbraunwarth/quiz/quiz"
 )
 
 var problemsPath string
@@ -15,27 +20,12 @@ var problemsPath string
 // a pair of a question and its corresponding answer is furthermore referred to as a problem
 func main() {
 	// parse flags and determine the problems file
-	flag.StringVar(&problemsPath, "problems", "problems.csv", "CSV file with problems and their answeres separated by commas")
+	flag.StringVar(&problemsPath, "problems", "problems.csv", "CSV, JSON or YAML file with problems and their answers")
 	flag.Parse()
 
-	// preparing csv file for reading
-	problemsFile, err := os.Open(problemsPath)
+	bank, err := quiz.LoadFile(problemsPath)
 	if err != nil {
-		log.Fatalf("error opening file: %s", err)
-	}
-	defer problemsFile.Close()
-
-	// parse csv content to records variable resulting in a 2D slice
-	r := csv.NewReader(problemsFile)
-	records, err := r.ReadAll()
-	if err != nil {
-		log.Fatalf("error reading csv: %s", err)
-	}
-
-	// fill problems map resulting in the form Question -> Answer [String -> String]
-	problems := make(map[string]string, len(records))
-	for _, row := range records {
-		problems[row[0]] = row[1]
+		log.Fatalf("error loading problems: %s", err)
 	}
 
 	// for each question store if the user answered correct
@@ -44,21 +34,21 @@ func main() {
 	// ask question(s) and take answer from input (single word/number)
 	// no response to user till all questions has been answered,
 	// but check for correctness and store either right or wrong answered
-	for question, answer := range problems {
+	for _, p := range bank.Problems {
 		// resetting user input to empty string
 		input := ""
 
-		fmt.Printf("%s = ", question)
+		fmt.Printf("%s = ", p.Question)
 		if _, err := fmt.Scanln(&input); err != nil {
 			log.Fatalf("error scanning user input: %s", err)
 		}
 
-		if input == answer {
+		if input == p.Answer {
 			correct++
 		}
 	}
 
 	// output total number of questions and those which were answered correctly
-	fmt.Printf("%d answered questions\n", len(problems))
+	fmt.Printf("%d answered questions\n", bank.Len())
 	fmt.Printf("%d questions answered correct\n", correct)
 }
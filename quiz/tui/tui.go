@@ -0,0 +1,255 @@
+// Package tui plays a quiz in an interactive terminal. Above each
+// question a status line shows a progress bar and a live countdown of
+// the global and the per-question time limit, answers are judged right
+// away or all together at the end, and the summary is colored.
+//
+// Only ANSI escape sequences are used, input is read line by line as in
+// plain mode.
+package tui
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/mbraunwarth/quiz/quiz"
+)
+
+// refresh is the interval the status line is redrawn in.
+const refresh = 100 * time.Millisecond
+
+// width is the width of the progress bar.
+const width = 30
+
+// ANSI escape sequences
+const (
+	reset  = "\033[0m"
+	bold   = "\033[1m"
+	red    = "\033[31m"
+	green  = "\033[32m"
+	yellow = "\033[33m"
+
+	// redrawing the line above the prompt without losing what the user
+	// typed so far: save the cursor, go up a line, clear it, write the
+	// status and restore the cursor
+	saveCursor    = "\0337"
+	restoreCursor = "\0338"
+	lineUp        = "\033[1A\r"
+	clearLine     = "\033[2K"
+)
+
+// Feedback tells when answers are judged.
+type Feedback string
+
+const (
+	// Immediate judges every answer right after it was given.
+	Immediate Feedback = "immediate"
+
+	// Deferred judges all answers together in the summary.
+	Deferred Feedback = "deferred"
+)
+
+// ParseFeedback returns the feedback mode with the given name.
+func ParseFeedback(name string) (Feedback, error) {
+	switch f := Feedback(name); f {
+	case Immediate, Deferred:
+		return f, nil
+	}
+	return "", fmt.Errorf("unknown feedback %q, use immediate or deferred", name)
+}
+
+// UI is the terminal user interface of a quiz. The countdowns are always
+// drawn by the wall clock.
+type UI struct {
+	Quiz     *quiz.Quiz
+	In       io.Reader
+	Out      io.Writer
+	Feedback Feedback
+}
+
+// Run plays the quiz until all questions are asked, the time is up, In
+// ends or ctx is done, which interrupts the quiz.
+func (u *UI) Run(ctx context.Context) (*quiz.Result, error) {
+	sess := u.Quiz.Start()
+	done := make(chan struct{})
+	defer close(done)
+	lines, errc := u.read(done)
+
+	ticker := time.NewTicker(refresh)
+	defer ticker.Stop()
+
+	for {
+		p, i, ok := sess.Next()
+		if !ok {
+			break
+		}
+		u.ask(sess, p, i)
+
+	wait:
+		for {
+			select {
+			case line, ok := <-lines:
+				if !ok {
+					sess.Stop()
+					return sess.Result(), <-errc
+				}
+
+				if strings.TrimSpace(line) == quiz.HintRequest && p.Hint != "" {
+					fmt.Fprintf(u.Out, "%shint: %s%s\n", yellow, p.Hint, reset)
+					u.ask(sess, p, i)
+					continue
+				}
+
+				rec, err := sess.Answer(i, line)
+				if errors.Is(err, quiz.ErrNotAsked) || errors.Is(err, quiz.ErrFinished) {
+					fmt.Fprintf(u.Out, "%stoo late!%s\n", red, reset)
+					break wait
+				} else if err != nil {
+					return sess.Result(), err
+				}
+				u.judge(p, rec)
+				break wait
+			case <-ticker.C:
+				// the session ran out of time for the question or as a whole
+				if sess.Done() || len(sess.Result().Records) != i {
+					fmt.Fprintf(u.Out, "\n%stime's up!%s\n", red, reset)
+					break wait
+				}
+				u.status(sess, i)
+			case <-ctx.Done():
+				sess.Stop()
+				res := sess.Result()
+				res.Interrupted = true
+				fmt.Fprintf(u.Out, "\n%squiz interrupted%s\n", yellow, reset)
+				return res, nil
+			}
+		}
+	}
+	return sess.Result(), nil
+}
+
+// ask shows the status line and the prompt of question i.
+func (u *UI) ask(sess *quiz.Session, p quiz.Problem, i int) {
+	fmt.Fprintf(u.Out, "\n%s\n%s%s%s", u.statusLine(sess, i), bold, p.Prompt(), reset)
+}
+
+// status redraws the status line above the prompt.
+func (u *UI) status(sess *quiz.Session, i int) {
+	// a multiple choice prompt spans several lines, so it isn't redrawn
+	if p := u.Quiz.Bank.Problems[i]; p.Type == quiz.TypeChoice {
+		return
+	}
+	fmt.Fprint(u.Out, saveCursor+lineUp+clearLine+u.statusLine(sess, i)+restoreCursor)
+}
+
+// statusLine returns the progress bar and the countdowns for question i.
+func (u *UI) statusLine(sess *quiz.Session, i int) string {
+	n := u.Quiz.Bank.Len()
+	done := width * i / n
+	line := fmt.Sprintf("[%s%s] %d/%d", strings.Repeat("#", done), strings.Repeat("-", width-done), i+1, n)
+
+	if d := sess.Deadline(); !d.IsZero() {
+		line += "   total " + countdown(time.Until(d))
+	}
+	if d := sess.QuestionDeadline(); !d.IsZero() {
+		line += "   question " + countdown(time.Until(d))
+	}
+	return line
+}
+
+// countdown formats the remaining time, turning red in the last seconds.
+func countdown(d time.Duration) string {
+	if d < 0 {
+		d = 0
+	}
+	s := fmt.Sprintf("%d:%04.1f", int(d.Minutes()), (d % time.Minute).Seconds())
+	if d < 5*time.Second {
+		return red + s + reset
+	}
+	return s
+}
+
+// judge shows whether an answer was right, unless feedback is deferred.
+func (u *UI) judge(p quiz.Problem, rec quiz.Record) {
+	if u.Feedback == Deferred {
+		return
+	}
+	if rec.Correct {
+		fmt.Fprintf(u.Out, "%s✔ correct%s\n", green, reset)
+	} else {
+		fmt.Fprintf(u.Out, "%s✘ wrong, expected %s%s\n", red, rec.Expected, reset)
+	}
+	if p.Explanation != "" {
+		fmt.Fprintln(u.Out, p.Explanation)
+	}
+}
+
+// read scans In line by line in the background until done is closed. The
+// error channel holds the error ending the input, if any, once the lines
+// channel is closed.
+func (u *UI) read(done <-chan struct{}) (<-chan string, <-chan error) {
+	lines := make(chan string)
+	errc := make(chan error, 1)
+	go func() {
+		defer close(lines)
+		s := bufio.NewScanner(u.In)
+		for s.Scan() {
+			select {
+			case lines <- s.Text():
+			case <-done:
+				return
+			}
+		}
+		errc <- s.Err()
+	}()
+	return lines, errc
+}
+
+// Summary writes the colored summary of res to w. With deferred feedback
+// all questions are listed as well, wrong and unanswered ones with their
+// explanation.
+func Summary(w io.Writer, res *quiz.Result, feedback Feedback) {
+	fmt.Fprintf(w, "\n%s----------------------------------------------%s\n", bold, reset)
+	if res.Interrupted {
+		fmt.Fprintf(w, "%spartial result of the interrupted quiz%s\n", yellow, reset)
+	}
+	if res.Expired {
+		fmt.Fprintf(w, "%stime expired%s\n", red, reset)
+	}
+
+	if feedback == Deferred {
+		for _, rec := range res.Records {
+			switch {
+			case rec.Correct:
+				fmt.Fprintf(w, "%s✔ %s = %s%s\n", green, rec.Question, rec.Given, reset)
+			case rec.Answered:
+				fmt.Fprintf(w, "%s✘ %s = %s, expected %s%s\n", red, rec.Question, rec.Given, rec.Expected, reset)
+			default:
+				fmt.Fprintf(w, "%s… %s unanswered, expected %s%s\n", yellow, rec.Question, rec.Expected, reset)
+			}
+			if !rec.Correct && rec.Explanation != "" {
+				fmt.Fprintf(w, "  %s\n", rec.Explanation)
+			}
+		}
+	}
+
+	color := red
+	if total := res.Total(); total > 0 && res.Correct()*2 >= total {
+		color = green
+	}
+	fmt.Fprintf(w, "%d questions answered from a total of %d questions\n", res.Answered(), res.Total())
+	fmt.Fprintf(w, "%s%s%d questions answered correct%s\n", bold, color, res.Correct(), reset)
+	cats := res.Categories()
+	if res.MaxScore() != res.Total() || len(cats) > 1 {
+		fmt.Fprintf(w, "%s%d of %d points scored%s\n", color, res.Score(), res.MaxScore(), reset)
+	}
+	if len(cats) > 1 {
+		for _, c := range cats {
+			fmt.Fprintf(w, "  %-20s %d/%d correct, %d/%d points\n", c.Category, c.Correct, c.Total, c.Score, c.MaxScore)
+		}
+	}
+}